@@ -0,0 +1,120 @@
+package main
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "strings"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// ball is one kind of Poke Ball catch can throw. item is its name on PokeAPI's item endpoint,
+// modifier multiplies the catch chance (the games' ball bonus) and the Master Ball never misses
+type ball struct {
+  name string
+  displayName string
+  item string
+  modifier float64
+  alwaysCatches bool
+}
+
+const defaultBall = "poke"
+
+var balls = map[string]ball{
+  "poke": {name: "poke", displayName: "Poke Ball", item: "poke-ball", modifier: 1},
+  "great": {name: "great", displayName: "Great Ball", item: "great-ball", modifier: 1.5},
+  "ultra": {name: "ultra", displayName: "Ultra Ball", item: "ultra-ball", modifier: 2},
+  "master": {name: "master", displayName: "Master Ball", item: "master-ball", modifier: 255, alwaysCatches: true},
+}
+
+// ballNames lists the names --ball takes, weakest ball first
+func ballNames() []string {
+  names := keysOf(balls)
+  sort.Slice(names, func(i, j int) bool {
+    return balls[names[i]].modifier < balls[names[j]].modifier
+  })
+
+  return names
+}
+
+func lookupBall(name string) (ball, error) {
+  b, found := balls[strings.TrimSuffix(name, "-ball")]
+  if !found {
+    return ball{}, fmt.Errorf("no ball called %q, pick one of %s", name, strings.Join(ballNames(), ", "))
+  }
+
+  return b, nil
+}
+
+// captureChance is the percentage chance of b catching a wild pokemon, using the Gen III+ capture formula:
+// a = (3*maxHP - 2*currentHP) * captureRate * ball / (3*maxHP), caught outright once a reaches 255,
+// otherwise the ball has to pass four shake checks of b/65536 each, where b = 1048560 / sqrt(sqrt(16711680/a)).
+// Status conditions don't exist here so their bonus is always 1
+func captureChance(captureRate int, b ball, maxHP int, currentHP int) float64 {
+  if b.alwaysCatches {
+    return 100
+  }
+
+  maxHP = max(maxHP, 1)
+  currentHP = max(1, min(currentHP, maxHP))
+
+  a := math.Floor(float64(3 * maxHP - 2 * currentHP) * float64(captureRate) * b.modifier / float64(3 * maxHP))
+  if a >= 255 {
+    return 100
+  }
+  if a < 1 {
+    a = 1
+  }
+
+  shake := math.Floor(1048560 / math.Sqrt(math.Sqrt(16711680 / a)))
+  return math.Pow(shake / 65536, 4) * 100
+}
+
+// baseStat is the pokemon's base value for the stat called name, e.g. "hp", or 0 if it doesn't have one
+func baseStat(pokemon pokeapi.Pokemon, name string) int {
+  for _, stat := range pokemon.Stats {
+    if stat.Stat.Name == name {
+      return stat.BaseStat
+    }
+  }
+
+  return 0
+}
+
+// parseCatchArgs splits catch's arguments into the pokemon and the ball, taking --ball NAME or --ball=NAME
+func parseCatchArgs(args []string) (string, ball, error) {
+  pokemonName := ""
+  ballName := defaultBall
+
+  for i := 0; i < len(args); i++ {
+    arg := args[i]
+    switch {
+    case arg == "--ball":
+      if i + 1 >= len(args) {
+        return "", ball{}, fmt.Errorf("--ball needs one of %s", strings.Join(ballNames(), ", "))
+      }
+      i++
+      ballName = args[i]
+    case strings.HasPrefix(arg, "--ball="):
+      ballName = strings.TrimPrefix(arg, "--ball=")
+    case strings.HasPrefix(arg, "-"):
+      return "", ball{}, fmt.Errorf("unknown option %s", arg)
+    case pokemonName == "":
+      pokemonName = arg
+    default:
+      return "", ball{}, fmt.Errorf("catch takes one pokemon, got %s and %s", pokemonName, arg)
+    }
+  }
+
+  if pokemonName == "" {
+    return "", ball{}, fmt.Errorf("which pokemon? e.g. catch pikachu --ball great")
+  }
+
+  b, err := lookupBall(ballName)
+  if err != nil {
+    return "", ball{}, err
+  }
+
+  return pokemonName, b, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "math"
+  "net/http"
+  "testing"
+)
+
+func TestCaptureChance(t *testing.T) {
+  cases := []struct {
+    name string
+    captureRate int
+    ball string
+    maxHP int
+    currentHP int
+    expected float64
+  }{
+    {name: "pikachu at full hp", captureRate: 190, ball: "poke", maxHP: 35, currentHP: 35, expected: 24.70},
+    {name: "great ball", captureRate: 190, ball: "great", maxHP: 35, currentHP: 35, expected: 37.25},
+    {name: "ultra ball", captureRate: 190, ball: "ultra", maxHP: 35, currentHP: 35, expected: 49.41},
+    {name: "legendary", captureRate: 3, ball: "poke", maxHP: 106, currentHP: 106, expected: 0.39},
+    {name: "low hp helps", captureRate: 255, ball: "poke", maxHP: 100, currentHP: 1, expected: 99.21},
+    {name: "a of 255 is a sure catch", captureRate: 255, ball: "ultra", maxHP: 100, currentHP: 1, expected: 100},
+    {name: "master ball", captureRate: 3, ball: "master", maxHP: 106, currentHP: 106, expected: 100},
+    {name: "capture rate of 0 still has a chance", captureRate: 0, ball: "poke", maxHP: 50, currentHP: 50, expected: 0.39},
+  }
+
+  for _, c := range cases {
+    b, err := lookupBall(c.ball)
+    if err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+    actual := captureChance(c.captureRate, b, c.maxHP, c.currentHP)
+    if math.Abs(actual - c.expected) > 0.01 {
+      t.Errorf("%s: expected %.2f%%, got %.2f%%", c.name, c.expected, actual)
+    }
+  }
+}
+
+func TestParseCatchArgs(t *testing.T) {
+  cases := []struct {
+    args []string
+    pokemon string
+    ball string
+    fails bool
+  }{
+    {args: []string{"pikachu"}, pokemon: "pikachu", ball: "poke"},
+    {args: []string{"pikachu", "--ball", "great"}, pokemon: "pikachu", ball: "great"},
+    {args: []string{"--ball=ultra-ball", "pikachu"}, pokemon: "pikachu", ball: "ultra"},
+    {args: []string{"pikachu", "--ball"}, fails: true},
+    {args: []string{"pikachu", "--ball", "premier"}, fails: true},
+    {args: []string{"pikachu", "bulbasaur"}, fails: true},
+    {args: []string{"--ball", "great"}, fails: true},
+  }
+
+  for _, c := range cases {
+    pokemon, b, err := parseCatchArgs(c.args)
+    if c.fails {
+      if err == nil {
+        t.Errorf("%v: expected an error", c.args)
+      }
+      continue
+    }
+    if err != nil {
+      t.Errorf("%v: unexpected error: %v", c.args, err)
+      continue
+    }
+    if pokemon != c.pokemon || b.name != c.ball {
+      t.Errorf("%v: expected %s with %s, got %s with %s", c.args, c.pokemon, c.ball, pokemon, b.name)
+    }
+  }
+}
+
+func TestMasterBallAlwaysCatches(t *testing.T) {
+  cfg, out := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    fmt.Fprint(w, `{"name": "mewtwo", "base_experience": 1500}`)
+  })
+  cfg.output = outputJSON
+  cfg.sandbox = true
+  cfg.bag["master-ball"] = 10
+
+  for i := 0; i < 10; i++ {
+    out.Reset()
+    if err := commandCatch(cfg)(context.Background(), []string{"mewtwo", "--ball", "master"}); err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+
+    var result catchResult
+    if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+      t.Fatalf("unexpected output %q: %v", out.String(), err)
+    }
+    if result.Outcome != "caught" || result.Ball != "master" || result.Chance != 100 {
+      t.Errorf("expected the master ball to catch, got %+v", result)
+    }
+  }
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "io"
+  "time"
+)
+
+// commandCache looks at and manages what's held in pokecache, mostly to answer
+// "why did that hit the network again?"
+func commandCache(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    switch args[0] {
+    case "stats":
+      stats := cfg.cache.Stats()
+      result := cacheStatsResult{
+        Entries: stats.Entries,
+        Bytes: stats.Bytes,
+        Hits: stats.Hits,
+        Misses: stats.Misses,
+        Evictions: stats.Evictions,
+        Expirations: stats.Expirations,
+        OldestAgeSeconds: stats.OldestAge.Seconds(),
+      }
+
+      return cfg.emit(result, func(w io.Writer) {
+        fmt.Fprintf(w, "Entries: %d\n", stats.Entries)
+        fmt.Fprintf(w, "Bytes: %d\n", stats.Bytes)
+        fmt.Fprintf(w, "Hits: %d\n", stats.Hits)
+        fmt.Fprintf(w, "Misses: %d\n", stats.Misses)
+        fmt.Fprintf(w, "Evictions: %d\n", stats.Evictions)
+        fmt.Fprintf(w, "Expirations: %d\n", stats.Expirations)
+        fmt.Fprintf(w, "Oldest entry: %s\n", stats.OldestAge.Round(time.Second))
+      })
+
+    case "list":
+      result := cacheListResult{Keys: cfg.cache.Keys()}
+
+      return cfg.emit(result, func(w io.Writer) {
+        if len(result.Keys) == 0 {
+          fmt.Fprintln(w, "The cache is empty")
+        }
+        for _, key := range result.Keys {
+          fmt.Fprintln(w, key)
+        }
+      })
+
+    case "evict":
+      if len(args) < 2 {
+        return errUsage
+      }
+
+      result := cacheEvictResult{Key: args[1], Evicted: cfg.cache.Delete(args[1])}
+
+      return cfg.emit(result, func(w io.Writer) {
+        if !result.Evicted {
+          fmt.Fprintf(w, "%s was not cached in memory\n", result.Key)
+          return
+        }
+        fmt.Fprintf(w, "Evicted %s\n", result.Key)
+      })
+
+    case "clear":
+      if err := cfg.cache.Clear(); err != nil {
+        return fmt.Errorf("error clearing the cache %w", err)
+      }
+
+      return cfg.emit(cacheClearResult{Cleared: true}, func(w io.Writer) {
+        fmt.Fprintln(w, "Cache cleared")
+      })
+
+    default:
+      return errUsage
+    }
+  }
+}
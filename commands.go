@@ -0,0 +1,290 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "sort"
+  "strings"
+)
+
+type cliCommand struct {
+  name string
+  description string
+  // usage shows the arguments, e.g. "explore <location-area>"
+  usage string
+  // minArgs and maxArgs are checked before the callback runs, maxArgs of -1 means no limit
+  minArgs int
+  maxArgs int
+  aliases []string
+  examples []string
+  callback func(context.Context, []string) error
+}
+
+// getCommands builds the registry every way of running commands (prompt, script, one-shot) goes through
+func getCommands(cfg *config) map[string]cliCommand {
+  commandsRegistry := make(map[string]cliCommand)
+
+  commandsRegistry["help"] = cliCommand{
+        name: "help",
+        description: "Displays a help message",
+        usage: "help [command]",
+        maxArgs: 1,
+        aliases: []string{"?"},
+        examples: []string{"help", "help catch"},
+        callback: commandHelp(cfg, commandsRegistry), // parentheses after commandHelp because it's returning a higher order funciton (closure)
+  }
+
+  commandsRegistry["exit"] = cliCommand{
+        name: "exit",
+        description: "Exit the Pokedex",
+        usage: "exit",
+        aliases: []string{"quit"},
+        callback: commandExit,
+  }
+
+  commandsRegistry["map"] = cliCommand {
+      name: "map",
+      description: "shows next 20 locations of the pokemon",
+      usage: "map",
+      callback: fetchLocations(cfg),
+  }
+
+  commandsRegistry["mapb"] = cliCommand {
+      name: "mapb",
+      description: "shows previous 20 locations of the pokemon",
+      usage: "mapb",
+      callback: fetchLocationsBackwards(cfg),
+  }
+
+  commandsRegistry["explore"] = cliCommand {
+      name: "explore",
+      description: "explore pokemons in a particular location by it's name",
+      usage: "explore <location-area>",
+      minArgs: 1,
+      maxArgs: 1,
+      examples: []string{"explore canalave-city-area"},
+      callback: commandExplore(cfg), // parentheses after commandExplore because this is also returning a higher order function like commandHelp (closure)
+  }
+
+  commandsRegistry["travel"] = cliCommand {
+      name: "travel",
+      description: "go to a location area without exploring it, catch only finds pokemon where you are",
+      usage: "travel <location-area>",
+      minArgs: 1,
+      maxArgs: 1,
+      examples: []string{"travel canalave-city-area"},
+      callback: commandTravel(cfg),
+  }
+
+  commandsRegistry["catch"] = cliCommand {
+      name: "catch",
+      description: "catch some pokemon living in the location area you're in",
+      usage: "catch <pokemon> [--ball poke|great|ultra|master]",
+      minArgs: 1,
+      maxArgs: 3,
+      examples: []string{"catch pikachu", "catch mewtwo --ball master"},
+      callback: commandCatch(cfg),
+  }
+
+  commandsRegistry["inspect"] = cliCommand {
+      name: "inspect",
+      description: "inspect details of the caught pokemon",
+      usage: "inspect <id|nickname|pokemon>",
+      minArgs: 1,
+      maxArgs: 1,
+      examples: []string{"inspect 3", "inspect sparky", "inspect pikachu"},
+      callback: commandInspect(cfg),
+  }
+
+  commandsRegistry["nickname"] = cliCommand {
+      name: "nickname",
+      description: "gives one of your pokemon a nickname, leave the name out to remove it",
+      usage: "nickname <id|nickname|pokemon> [name]",
+      minArgs: 1,
+      maxArgs: 2,
+      examples: []string{"nickname 3 sparky", "nickname sparky"},
+      callback: commandNickname(cfg),
+  }
+
+  commandsRegistry["pokedex"] = cliCommand {
+      name: "pokedex",
+      description: "prints a list of all the pokemon the user has caught",
+      usage: "pokedex",
+      aliases: []string{"dex"},
+      callback: commandPokedex(cfg),
+  }
+
+  commandsRegistry["mart"] = cliCommand {
+      name: "mart",
+      description: "lists what the Poke Mart sells and for how much",
+      usage: "mart",
+      aliases: []string{"shop"},
+      callback: commandMart(cfg),
+  }
+
+  commandsRegistry["buy"] = cliCommand {
+      name: "buy",
+      description: "buys items from the Poke Mart",
+      usage: "buy <item> [quantity]",
+      minArgs: 1,
+      maxArgs: 2,
+      examples: []string{"buy poke-ball 10", "buy potion"},
+      callback: commandBuy(cfg),
+  }
+
+  commandsRegistry["bag"] = cliCommand {
+      name: "bag",
+      description: "shows your money and the items you're carrying, every catch uses up a ball",
+      usage: "bag",
+      aliases: []string{"inventory"},
+      callback: commandBag(cfg),
+  }
+
+  commandsRegistry["save"] = cliCommand {
+      name: "save",
+      description: "saves the pokemon you've caught to the save file",
+      usage: "save",
+      callback: commandSave(cfg),
+  }
+
+  commandsRegistry["load"] = cliCommand {
+      name: "load",
+      description: "loads the pokemon you've caught from the save file",
+      usage: "load",
+      callback: commandLoad(cfg),
+  }
+
+  commandsRegistry["cache"] = cliCommand {
+      name: "cache",
+      description: "looks at what's cached from PokeAPI",
+      usage: "cache stats|list|evict <url>|clear",
+      minArgs: 1,
+      maxArgs: 2,
+      examples: []string{"cache stats", "cache evict https://pokeapi.co/api/v2/pokemon/pikachu"},
+      callback: commandCache(cfg),
+  }
+
+  commandsRegistry["seed"] = cliCommand {
+      name: "seed",
+      description: "shows the random seed, or starts the random rolls over from a new one",
+      usage: "seed [number]",
+      maxArgs: 1,
+      examples: []string{"seed", "seed 42"},
+      callback: commandSeed(cfg),
+  }
+
+  commandsRegistry["offline"] = cliCommand {
+      name: "offline",
+      description: "only use cached and fixture data instead of the network",
+      usage: "offline [on|off]",
+      maxArgs: 1,
+      examples: []string{"offline on"},
+      callback: commandOffline(cfg),
+  }
+
+  return commandsRegistry
+}
+
+// lookupCommand finds a command by its name or one of its aliases
+func lookupCommand(commands map[string]cliCommand, word string) (cliCommand, bool) {
+  if command, found := commands[word]; found {
+    return command, true
+  }
+
+  for _, command := range commands {
+    for _, alias := range command.aliases {
+      if alias == word {
+        return command, true
+      }
+    }
+  }
+
+  return cliCommand{}, false
+}
+
+// errUsage is what a callback returns when its arguments are the right count but still don't make sense,
+// e.g. an unknown subcommand. executeCommand turns it into the same error checkArgs gives
+var errUsage = errors.New("usage")
+
+// usageError is how every command complains about its arguments
+func (c cliCommand) usageError() error {
+  return fmt.Errorf("%w: %s", errUsage, c.usage)
+}
+
+// checkArgs is the one place argument counts are validated, so every command complains the same way
+func (c cliCommand) checkArgs(args []string) error {
+  if len(args) < c.minArgs || (c.maxArgs >= 0 && len(args) > c.maxArgs) {
+    return c.usageError()
+  }
+
+  return nil
+}
+
+// sortedCommands lists every command once, ordered by name
+func sortedCommands(commands map[string]cliCommand) []cliCommand {
+  sorted := make([]cliCommand, 0, len(commands))
+  for _, command := range commands {
+    sorted = append(sorted, command)
+  }
+  sort.Slice(sorted, func(i, j int) bool {
+    return sorted[i].name < sorted[j].name
+  })
+
+  return sorted
+}
+
+func toHelpCommand(command cliCommand) helpCommand {
+  return helpCommand{
+    Name: command.name,
+    Description: command.description,
+    Usage: command.usage,
+    Aliases: append([]string{}, command.aliases...),
+    Examples: append([]string{}, command.examples...),
+  }
+}
+
+func commandHelp(cfg *config, commands map[string]cliCommand) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if len(args) == 1 {
+      command, found := lookupCommand(commands, args[0])
+      if !found {
+        return didYouMean(fmt.Errorf("%w %q", errUnknownCommand, args[0]), args[0], commandNames(commands))
+      }
+
+      result := toHelpCommand(command)
+      return cfg.emit(result, func(w io.Writer) {
+        fmt.Fprintf(w, "%s: %s\n", result.Name, result.Description)
+        fmt.Fprintf(w, "Usage: %s\n", result.Usage)
+        if len(result.Aliases) > 0 {
+          fmt.Fprintf(w, "Aliases: %s\n", strings.Join(result.Aliases, ", "))
+        }
+        if len(result.Examples) > 0 {
+          fmt.Fprintln(w, "Examples:")
+          for _, example := range result.Examples {
+            fmt.Fprintf(w, "  %s\n", example)
+          }
+        }
+      })
+    }
+
+    result := helpResult{Commands: []helpCommand{}}
+    for _, command := range sortedCommands(commands) {
+      result.Commands = append(result.Commands, toHelpCommand(command))
+    }
+
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintln(w, "Welcome to the Pokedex!")
+      fmt.Fprintln(w, "Usage:")
+      fmt.Fprintln(w, "")
+
+      for _, v := range result.Commands {
+        fmt.Fprintf(w, "%s: %s\n", v.Usage, v.Description)
+      }
+
+      fmt.Fprintln(w, "")
+      fmt.Fprintln(w, "Use help <command> for more about one command.")
+    })
+  }
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+  "sort"
+  "strings"
+)
+
+func (cfg *config) rememberLocationArea(name string) {
+  if cfg.seenLocationAreas == nil {
+    cfg.seenLocationAreas = make(map[string]bool)
+  }
+  cfg.seenLocationAreas[name] = true
+}
+
+func (cfg *config) rememberPokemon(name string) {
+  if cfg.seenPokemon == nil {
+    cfg.seenPokemon = make(map[string]bool)
+  }
+  cfg.seenPokemon[name] = true
+}
+
+// completer completes command names, and after a command the arguments that make sense for it:
+// location areas already listed by map, pokemon found by explore and pokemon in the Pokedex
+func completer(cfg *config, commands map[string]cliCommand) func(string) []string {
+  return func(line string) []string {
+    words := strings.Fields(line)
+    endsWithSpace := strings.HasSuffix(line, " ")
+
+    // still typing the command itself
+    if len(words) == 0 || (len(words) == 1 && !endsWithSpace) {
+      word := ""
+      if len(words) == 1 {
+        word = words[0]
+      }
+      return withPrefix(strings.TrimSuffix(line, word), word, keysOf(commands))
+    }
+
+    // catch's --ball takes a ball name wherever it comes
+    if words[0] == "catch" {
+      if endsWithSpace && words[len(words) - 1] == "--ball" {
+        return withPrefix(line, "", ballNames())
+      }
+      if !endsWithSpace && len(words) > 2 && words[len(words) - 2] == "--ball" {
+        word := words[len(words) - 1]
+        return withPrefix(strings.TrimSuffix(line, word), word, ballNames())
+      }
+    }
+
+    // only the first argument is completed
+    if len(words) > 2 || (len(words) == 2 && endsWithSpace) {
+      return nil
+    }
+
+    word := ""
+    if len(words) == 2 {
+      word = words[1]
+    }
+
+    var options []string
+    switch words[0] {
+    case "explore", "travel":
+      options = keysOf(cfg.seenLocationAreas)
+    case "catch":
+      options = keysOf(cfg.seenPokemon)
+      if !cfg.sandbox && cfg.currentEncounters != nil {
+        options = cfg.currentEncounters
+      }
+    case "inspect", "nickname":
+      options = cfg.ownedNames()
+    case "help":
+      options = keysOf(commands)
+    case "buy":
+      options = martStock
+    case "cache":
+      options = []string{"stats", "list", "evict", "clear"}
+    case "offline":
+      options = []string{"on", "off"}
+    }
+
+    return withPrefix(strings.TrimSuffix(line, word), word, options)
+  }
+}
+
+// withPrefix keeps the options starting with word and puts head back in front of them
+func withPrefix(head string, word string, options []string) []string {
+  candidates := []string{}
+  for _, option := range options {
+    if strings.HasPrefix(option, word) {
+      candidates = append(candidates, head + option)
+    }
+  }
+  sort.Strings(candidates)
+
+  return candidates
+}
+
+func keysOf[V any](m map[string]V) []string {
+  keys := make([]string, 0, len(m))
+  for key := range m {
+    keys = append(keys, key)
+  }
+
+  return keys
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+  "testing"
+)
+
+func TestCompleter(t *testing.T) {
+  cfg := &config{
+    ownedPokemon: []ownedPokemon{{ID: 1, Species: "pikachu"}},
+  }
+  cfg.rememberLocationArea("canalave-city-area")
+  cfg.rememberLocationArea("eterna-city-area")
+  cfg.rememberPokemon("tentacool")
+
+  commands := map[string]cliCommand{
+    "exit": {name: "exit"},
+    "explore": {name: "explore"},
+    "inspect": {name: "inspect"},
+    "catch": {name: "catch"},
+  }
+  complete := completer(cfg, commands)
+
+  cases := []struct {
+    line string
+    expected []string
+  }{
+    {
+      line: "ex",
+      expected: []string{"exit", "explore"},
+    },
+    {
+      line: "explore ",
+      expected: []string{"explore canalave-city-area", "explore eterna-city-area"},
+    },
+    {
+      line: "explore e",
+      expected: []string{"explore eterna-city-area"},
+    },
+    {
+      line: "catch t",
+      expected: []string{"catch tentacool"},
+    },
+    {
+      line: "catch tentacool --ball ",
+      expected: []string{"catch tentacool --ball great", "catch tentacool --ball master", "catch tentacool --ball poke", "catch tentacool --ball ultra"},
+    },
+    {
+      line: "catch tentacool --ball u",
+      expected: []string{"catch tentacool --ball ultra"},
+    },
+    {
+      line: "inspect ",
+      expected: []string{"inspect pikachu"},
+    },
+    {
+      line: "inspect pikachu ",
+      expected: nil,
+    },
+  }
+
+  for _, c := range cases {
+    actual := complete(c.line)
+    if len(actual) != len(c.expected) {
+      t.Errorf("%q: expected %v, got %v", c.line, c.expected, actual)
+      continue
+    }
+
+    for i := range actual {
+      if actual[i] != c.expected[i] {
+        t.Errorf("%q: expected %v, got %v", c.line, c.expected, actual)
+      }
+    }
+  }
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// friendlyError prints msg instead of err's url-laden message, but errors.Is still sees err underneath
+type friendlyError struct {
+  msg string
+  err error
+}
+
+func (e *friendlyError) Error() string {
+  return e.msg
+}
+
+func (e *friendlyError) Unwrap() error {
+  return e.err
+}
+
+// describeFetchError turns the fetch layer's typed errors into something a person can act on.
+// kind and name say what was being looked up, e.g. "Pokemon" and "pikachuu"
+func describeFetchError(err error, kind, name string) error {
+  switch {
+  case errors.Is(err, pokeapi.ErrNotFound):
+    return &friendlyError{msg: fmt.Sprintf("no %s named '%s'", kind, name), err: err}
+  case errors.Is(err, pokeapi.ErrRateLimited):
+    return &friendlyError{msg: "PokeAPI is rate limiting requests, try again in a little while", err: err}
+  case errors.Is(err, pokeapi.ErrUpstream):
+    return &friendlyError{msg: "PokeAPI is having trouble right now, try again later", err: err}
+  }
+
+  return err
+}
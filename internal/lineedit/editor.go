@@ -0,0 +1,515 @@
+package lineedit
+
+import (
+  "bufio"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "strings"
+  "unicode"
+  "unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C is pressed
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer gets the line up to the cursor and returns every full line it could be completed to,
+// e.g. "ex" could give []string{"exit", "explore"}
+type Completer func(line string) []string
+
+const (
+  keyCtrlA = 1
+  keyCtrlB = 2
+  keyCtrlC = 3
+  keyCtrlD = 4
+  keyCtrlE = 5
+  keyCtrlF = 6
+  keyCtrlG = 7
+  keyCtrlH = 8
+  keyTab = 9
+  keyCtrlJ = 10
+  keyCtrlK = 11
+  keyCtrlL = 12
+  keyEnter = 13
+  keyCtrlN = 14
+  keyCtrlP = 16
+  keyCtrlR = 18
+  keyCtrlU = 21
+  keyCtrlW = 23
+  keyEsc = 27
+  keyBackspace = 127
+)
+
+// Editor reads lines from a terminal with emacs style editing, history and tab completion.
+// When in isn't a terminal it just reads plain lines, so piping input still works
+type Editor struct {
+  in *bufio.Reader
+  out io.Writer
+  fd int
+  terminal bool
+
+  completer Completer
+  history []string
+  historyFile string
+  maxHistory int
+}
+
+func NewEditor(in io.Reader, out io.Writer) *Editor {
+
+  editor := &Editor {
+    in: bufio.NewReader(in),
+    out: out,
+    maxHistory: 1000,
+  }
+
+  if file, ok := in.(*os.File); ok && isTerminal(int(file.Fd())) {
+    editor.fd = int(file.Fd())
+    editor.terminal = true
+  }
+
+  return editor
+
+}
+
+func (e *Editor) SetCompleter(completer Completer) {
+  e.completer = completer
+}
+
+// ReadLine shows prompt and returns the line typed without its newline.
+// It returns io.EOF on Ctrl-D at an empty line (or the end of piped input), and ErrInterrupted
+// along with whatever had been typed so far on Ctrl-C
+func (e *Editor) ReadLine(prompt string) (string, error) {
+  if !e.terminal {
+    return e.readPlain(prompt)
+  }
+
+  restore, err := makeRaw(e.fd)
+  if err != nil {
+    return e.readPlain(prompt)
+  }
+  defer restore()
+
+  return e.edit(prompt)
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+  fmt.Fprint(e.out, prompt)
+
+  line, err := e.in.ReadString('\n')
+  if err != nil && (err != io.EOF || line == "") {
+    return "", err
+  }
+
+  return strings.TrimRight(line, "\r\n"), nil
+}
+
+// lineState is the line being edited, pos is the cursor as an index into buf
+type lineState struct {
+  prompt string
+  buf []rune
+  pos int
+}
+
+func (s *lineState) insert(r rune) {
+  s.buf = append(s.buf, 0)
+  copy(s.buf[s.pos + 1:], s.buf[s.pos:])
+  s.buf[s.pos] = r
+  s.pos++
+}
+
+func (s *lineState) backspace() {
+  if s.pos == 0 {
+    return
+  }
+
+  s.buf = append(s.buf[:s.pos - 1], s.buf[s.pos:]...)
+  s.pos--
+}
+
+func (s *lineState) deleteForward() {
+  if s.pos == len(s.buf) {
+    return
+  }
+
+  s.buf = append(s.buf[:s.pos], s.buf[s.pos + 1:]...)
+}
+
+// deleteWord removes the word before the cursor along with the spaces after it, like Ctrl-W in a shell
+func (s *lineState) deleteWord() {
+  start := s.pos
+  for start > 0 && s.buf[start - 1] == ' ' {
+    start--
+  }
+  for start > 0 && s.buf[start - 1] != ' ' {
+    start--
+  }
+
+  s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+  s.pos = start
+}
+
+func (s *lineState) set(line string) {
+  s.buf = []rune(line)
+  s.pos = len(s.buf)
+}
+
+func (e *Editor) edit(prompt string) (string, error) {
+  s := &lineState{prompt: prompt}
+
+  // historyIndex == len(e.history) means we're on the line being typed, which is stashed in pending while browsing
+  historyIndex := len(e.history)
+  pending := ""
+  browse := func(delta int) {
+    next := historyIndex + delta
+    if next < 0 || next > len(e.history) {
+      return
+    }
+
+    if historyIndex == len(e.history) {
+      pending = string(s.buf)
+    }
+    historyIndex = next
+
+    if historyIndex == len(e.history) {
+      s.set(pending)
+    } else {
+      s.set(e.history[historyIndex])
+    }
+  }
+
+  lastWasTab := false
+  e.refresh(s)
+
+  for {
+    r, _, err := e.in.ReadRune()
+    if err != nil {
+      return "", err
+    }
+
+    wasTab := false
+
+    switch r {
+    case keyEnter, keyCtrlJ:
+      fmt.Fprint(e.out, "\r\n")
+      return string(s.buf), nil
+
+    case keyCtrlC:
+      fmt.Fprint(e.out, "^C\r\n")
+      return string(s.buf), ErrInterrupted
+
+    case keyCtrlD:
+      if len(s.buf) == 0 {
+        fmt.Fprint(e.out, "\r\n")
+        return "", io.EOF
+      }
+      s.deleteForward()
+
+    case keyBackspace, keyCtrlH:
+      s.backspace()
+
+    case keyCtrlA:
+      s.pos = 0
+
+    case keyCtrlE:
+      s.pos = len(s.buf)
+
+    case keyCtrlB:
+      if s.pos > 0 {
+        s.pos--
+      }
+
+    case keyCtrlF:
+      if s.pos < len(s.buf) {
+        s.pos++
+      }
+
+    case keyCtrlK:
+      s.buf = s.buf[:s.pos]
+
+    case keyCtrlU:
+      s.buf = s.buf[s.pos:]
+      s.pos = 0
+
+    case keyCtrlW:
+      s.deleteWord()
+
+    case keyCtrlL:
+      fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+
+    case keyCtrlP:
+      browse(-1)
+
+    case keyCtrlN:
+      browse(1)
+
+    case keyTab:
+      e.complete(s, lastWasTab)
+      wasTab = true
+
+    case keyCtrlR:
+      submit, err := e.search(s)
+      if err != nil {
+        return "", err
+      }
+      if submit {
+        fmt.Fprint(e.out, "\r\n")
+        return string(s.buf), nil
+      }
+
+    case keyEsc:
+      key, err := e.readEscape()
+      if err != nil {
+        return "", err
+      }
+
+      switch key {
+      case "up":
+        browse(-1)
+      case "down":
+        browse(1)
+      case "right":
+        if s.pos < len(s.buf) {
+          s.pos++
+        }
+      case "left":
+        if s.pos > 0 {
+          s.pos--
+        }
+      case "home":
+        s.pos = 0
+      case "end":
+        s.pos = len(s.buf)
+      case "delete":
+        s.deleteForward()
+      }
+
+    default:
+      if unicode.IsPrint(r) {
+        s.insert(r)
+      }
+    }
+
+    lastWasTab = wasTab
+    e.refresh(s)
+  }
+}
+
+// readEscape reads the rest of an escape sequence (the ESC is already read) and names the key it stands for,
+// unknown sequences come back as ""
+func (e *Editor) readEscape() (string, error) {
+  r, _, err := e.in.ReadRune()
+  if err != nil {
+    return "", err
+  }
+  if r != '[' && r != 'O' {
+    return "", nil
+  }
+
+  r, _, err = e.in.ReadRune()
+  if err != nil {
+    return "", err
+  }
+
+  switch r {
+  case 'A':
+    return "up", nil
+  case 'B':
+    return "down", nil
+  case 'C':
+    return "right", nil
+  case 'D':
+    return "left", nil
+  case 'H':
+    return "home", nil
+  case 'F':
+    return "end", nil
+  }
+
+  if r < '0' || r > '9' {
+    return "", nil
+  }
+
+  // sequences like ESC [ 3 ~ carry a number before the ~
+  code := string(r)
+  for {
+    r, _, err = e.in.ReadRune()
+    if err != nil {
+      return "", err
+    }
+    if r == '~' {
+      break
+    }
+    if r < '0' || r > '9' {
+      return "", nil
+    }
+    code += string(r)
+  }
+
+  switch code {
+  case "1", "7":
+    return "home", nil
+  case "4", "8":
+    return "end", nil
+  case "3":
+    return "delete", nil
+  }
+
+  return "", nil
+}
+
+// complete fills in the only completion, or as much as all completions share.
+// When that doesn't add anything, a second tab in a row lists them
+func (e *Editor) complete(s *lineState, listAll bool) {
+  if e.completer == nil {
+    return
+  }
+
+  head := string(s.buf[:s.pos])
+  tail := string(s.buf[s.pos:])
+
+  candidates := e.completer(head)
+  if len(candidates) == 0 {
+    fmt.Fprint(e.out, "\a")
+    return
+  }
+
+  if len(candidates) == 1 {
+    completed := candidates[0]
+    if !strings.HasPrefix(tail, " ") {
+      completed += " "
+    }
+
+    s.set(completed)
+    s.buf = append(s.buf, []rune(tail)...)
+    return
+  }
+
+  if prefix := commonPrefix(candidates); len(prefix) > len(head) {
+    s.set(prefix)
+    s.buf = append(s.buf, []rune(tail)...)
+    return
+  }
+
+  if !listAll {
+    fmt.Fprint(e.out, "\a")
+    return
+  }
+
+  // only the word being completed is worth showing
+  wordStart := strings.LastIndex(head, " ") + 1
+  words := make([]string, 0, len(candidates))
+  for _, candidate := range candidates {
+    if len(candidate) >= wordStart {
+      candidate = candidate[wordStart:]
+    }
+    words = append(words, candidate)
+  }
+
+  fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(words, "  "))
+}
+
+func commonPrefix(candidates []string) string {
+  prefix := candidates[0]
+  for _, candidate := range candidates[1:] {
+    for !strings.HasPrefix(candidate, prefix) {
+      prefix = prefix[:len(prefix) - 1]
+    }
+  }
+
+  // never cut a multi byte character in half
+  for !utf8.ValidString(prefix) {
+    prefix = prefix[:len(prefix) - 1]
+  }
+
+  return prefix
+}
+
+// search is Ctrl-R reverse incremental search through the history.
+// Enter runs the match straight away, Ctrl-G or Ctrl-C go back to the line as it was,
+// and any other key keeps the match and carries on editing with that key
+func (e *Editor) search(s *lineState) (bool, error) {
+  original := string(s.buf)
+  query := ""
+  matchIndex := len(e.history)
+  match := ""
+  failing := false
+
+  find := func(from int) {
+    for i := from; i >= 0; i-- {
+      if i < len(e.history) && strings.Contains(e.history[i], query) {
+        matchIndex = i
+        match = e.history[i]
+        failing = false
+        return
+      }
+    }
+    failing = true
+  }
+
+  for {
+    label := "reverse-i-search"
+    if failing {
+      label = "failing " + label
+    }
+    fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", label, query, match)
+
+    r, _, err := e.in.ReadRune()
+    if err != nil {
+      return false, err
+    }
+
+    switch {
+    case r == keyEnter || r == keyCtrlJ:
+      s.set(match)
+      return true, nil
+
+    case r == keyCtrlG || r == keyCtrlC:
+      s.set(original)
+      return false, nil
+
+    case r == keyCtrlR:
+      if query != "" {
+        find(matchIndex - 1)
+      }
+
+    case r == keyBackspace || r == keyCtrlH:
+      if query != "" {
+        _, size := utf8.DecodeLastRuneInString(query)
+        query = query[:len(query) - size]
+        matchIndex = len(e.history)
+        match = ""
+        failing = false
+        if query != "" {
+          find(len(e.history) - 1)
+        }
+      }
+
+    case unicode.IsPrint(r):
+      query += string(r)
+      find(matchIndex)
+
+    default:
+      if match != "" {
+        s.set(match)
+      }
+      e.in.UnreadRune()
+      return false, nil
+    }
+  }
+}
+
+// refresh redraws the whole line and puts the cursor back where it belongs
+func (e *Editor) refresh(s *lineState) {
+  var b strings.Builder
+  b.WriteString("\r")
+  b.WriteString(s.prompt)
+  b.WriteString(string(s.buf))
+  b.WriteString("\x1b[K\r")
+
+  if column := utf8.RuneCountInString(s.prompt) + s.pos; column > 0 {
+    fmt.Fprintf(&b, "\x1b[%dC", column)
+  }
+
+  io.WriteString(e.out, b.String())
+}
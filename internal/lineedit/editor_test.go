@@ -0,0 +1,162 @@
+package lineedit
+
+import (
+  "errors"
+  "io"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// editKeys runs the editor over keys as if they were typed into a terminal
+func editKeys(t *testing.T, e *Editor, keys string) (string, error) {
+  t.Helper()
+  e.in.Reset(strings.NewReader(keys))
+  return e.edit("> ")
+}
+
+func TestEditKeys(t *testing.T) {
+  cases := []struct {
+    name string
+    keys string
+    expected string
+  }{
+    {
+      name: "plain typing",
+      keys: "catch pikachu\r",
+      expected: "catch pikachu",
+    },
+    {
+      name: "backspace",
+      keys: "catchh\x7f pikachu\r",
+      expected: "catch pikachu",
+    },
+    {
+      name: "insert after moving left",
+      keys: "cach\x1b[D\x1b[Dt\r",
+      expected: "catch",
+    },
+    {
+      name: "home and end",
+      keys: "pikachu\x01catch \x05!\r",
+      expected: "catch pikachu!",
+    },
+    {
+      name: "delete word",
+      keys: "catch pikachu\x17mew\r",
+      expected: "catch mew",
+    },
+    {
+      name: "kill to the start",
+      keys: "catch pikachu\x15exit\r",
+      expected: "exit",
+    },
+  }
+
+  for _, c := range cases {
+    t.Run(c.name, func(t *testing.T) {
+      e := NewEditor(strings.NewReader(""), io.Discard)
+      actual, err := editKeys(t, e, c.keys)
+      if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+      }
+      if actual != c.expected {
+        t.Errorf("expected %q, got %q", c.expected, actual)
+      }
+    })
+  }
+}
+
+func TestEditControlKeys(t *testing.T) {
+  e := NewEditor(strings.NewReader(""), io.Discard)
+
+  if _, err := editKeys(t, e, "\x04"); err != io.EOF {
+    t.Errorf("expected io.EOF on Ctrl-D at an empty line, got %v", err)
+  }
+
+  if _, err := editKeys(t, e, "map\x03"); !errors.Is(err, ErrInterrupted) {
+    t.Errorf("expected ErrInterrupted on Ctrl-C, got %v", err)
+  }
+}
+
+func TestHistoryBrowsingAndSearch(t *testing.T) {
+  e := NewEditor(strings.NewReader(""), io.Discard)
+  e.AddHistory("explore canalave-city-area")
+  e.AddHistory("catch pikachu")
+  e.AddHistory("catch pikachu")
+  e.AddHistory("inspect pikachu")
+
+  if len(e.history) != 3 {
+    t.Errorf("expected repeated lines to be skipped, got %v", e.history)
+  }
+
+  line, _ := editKeys(t, e, "\x1b[A\x1b[A\r")
+  if line != "catch pikachu" {
+    t.Errorf("expected two ups to give the second newest line, got %q", line)
+  }
+
+  line, _ = editKeys(t, e, "map\x1b[A\x1b[B\r")
+  if line != "map" {
+    t.Errorf("expected down to bring back the line being typed, got %q", line)
+  }
+
+  line, _ = editKeys(t, e, "\x12canal\r")
+  if line != "explore canalave-city-area" {
+    t.Errorf("expected Ctrl-R to find the explore line, got %q", line)
+  }
+
+  line, _ = editKeys(t, e, "\x12pika\x12\x05 now\r")
+  if line != "catch pikachu now" {
+    t.Errorf("expected a second Ctrl-R to find an older match and keep editing, got %q", line)
+  }
+}
+
+func TestHistoryFile(t *testing.T) {
+  historyFile := filepath.Join(t.TempDir(), "history")
+
+  first := NewEditor(strings.NewReader(""), io.Discard)
+  if err := first.SetHistoryFile(historyFile); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  first.AddHistory("map")
+  first.AddHistory("explore canalave-city-area")
+
+  second := NewEditor(strings.NewReader(""), io.Discard)
+  if err := second.SetHistoryFile(historyFile); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  line, _ := editKeys(t, second, "\x1b[A\r")
+  if line != "explore canalave-city-area" {
+    t.Errorf("expected the history to survive a restart, got %q", line)
+  }
+}
+
+func TestTabCompletion(t *testing.T) {
+  e := NewEditor(strings.NewReader(""), io.Discard)
+  e.SetCompleter(func(line string) []string {
+    all := []string{"exit", "explore", "explore canalave-city-area", "explore eterna-city-area"}
+    candidates := []string{}
+    for _, candidate := range all {
+      if strings.HasPrefix(candidate, line) && strings.Count(candidate, " ") == strings.Count(line, " ") {
+        candidates = append(candidates, candidate)
+      }
+    }
+    return candidates
+  })
+
+  line, _ := editKeys(t, e, "expl\t\r")
+  if line != "explore " {
+    t.Errorf("expected the only match to be completed, got %q", line)
+  }
+
+  line, _ = editKeys(t, e, "e\t\r")
+  if line != "ex" {
+    t.Errorf("expected the common prefix to be completed, got %q", line)
+  }
+
+  line, _ = editKeys(t, e, "explore c\t\r")
+  if line != "explore canalave-city-area " {
+    t.Errorf("expected the argument to be completed, got %q", line)
+  }
+}
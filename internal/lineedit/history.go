@@ -0,0 +1,78 @@
+package lineedit
+
+import (
+  "bufio"
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "strings"
+)
+
+// SetHistoryFile loads the history saved in path, every line added afterwards is appended to it
+func (e *Editor) SetHistoryFile(path string) error {
+  e.historyFile = path
+
+  file, err := os.Open(path)
+  if errors.Is(err, os.ErrNotExist) {
+    return nil
+  }
+  if err != nil {
+    return fmt.Errorf("error opening history file %w", err)
+  }
+  defer file.Close()
+
+  scanner := bufio.NewScanner(file)
+  for scanner.Scan() {
+    if line := scanner.Text(); line != "" {
+      e.history = append(e.history, line)
+    }
+  }
+  if err := scanner.Err(); err != nil {
+    return fmt.Errorf("error reading history file %w", err)
+  }
+
+  // the file only ever grows while appending, so trim it back down whenever it's loaded
+  if len(e.history) > e.maxHistory {
+    e.history = e.history[len(e.history) - e.maxHistory:]
+    return os.WriteFile(path, []byte(strings.Join(e.history, "\n") + "\n"), 0o600)
+  }
+
+  return nil
+}
+
+// AddHistory remembers line for the up arrow and Ctrl-R, blank lines and repeats of the last line are skipped
+func (e *Editor) AddHistory(line string) error {
+  line = strings.TrimSpace(line)
+  if line == "" || strings.ContainsAny(line, "\r\n") {
+    return nil
+  }
+  if len(e.history) > 0 && e.history[len(e.history) - 1] == line {
+    return nil
+  }
+
+  e.history = append(e.history, line)
+  if len(e.history) > e.maxHistory {
+    e.history = e.history[1:]
+  }
+
+  if e.historyFile == "" {
+    return nil
+  }
+
+  if err := os.MkdirAll(filepath.Dir(e.historyFile), 0o755); err != nil {
+    return fmt.Errorf("error creating history directory %w", err)
+  }
+
+  file, err := os.OpenFile(e.historyFile, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0o600)
+  if err != nil {
+    return fmt.Errorf("error opening history file %w", err)
+  }
+  defer file.Close()
+
+  if _, err := fmt.Fprintln(file, line); err != nil {
+    return fmt.Errorf("error writing history file %w", err)
+  }
+
+  return nil
+}
@@ -0,0 +1,12 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package lineedit
+
+import (
+  "syscall"
+)
+
+const (
+  ioctlGetTermios = syscall.TIOCGETA
+  ioctlSetTermios = syscall.TIOCSETA
+)
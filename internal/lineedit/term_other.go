@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package lineedit
+
+import (
+  "errors"
+)
+
+// other platforms don't get line editing, ReadLine falls back to reading plain lines
+
+func isTerminal(fd int) bool {
+  return false
+}
+
+func makeRaw(fd int) (func(), error) {
+  return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,57 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package lineedit
+
+import (
+  "syscall"
+  "unsafe"
+)
+
+func getTermios(fd int) (*syscall.Termios, error) {
+  termios := &syscall.Termios{}
+  _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlGetTermios, uintptr(unsafe.Pointer(termios)))
+  if errno != 0 {
+    return nil, errno
+  }
+
+  return termios, nil
+}
+
+func setTermios(fd int, termios *syscall.Termios) error {
+  _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(termios)))
+  if errno != 0 {
+    return errno
+  }
+
+  return nil
+}
+
+func isTerminal(fd int) bool {
+  _, err := getTermios(fd)
+  return err == nil
+}
+
+// makeRaw turns off echo, line buffering and signal keys so every key press reaches the editor,
+// output processing stays on so "\n" still moves to the start of the next line
+func makeRaw(fd int) (func(), error) {
+  original, err := getTermios(fd)
+  if err != nil {
+    return nil, err
+  }
+
+  raw := *original
+  raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+  raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+  raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+  raw.Cflag |= syscall.CS8
+  raw.Cc[syscall.VMIN] = 1
+  raw.Cc[syscall.VTIME] = 0
+
+  if err := setTermios(fd, &raw); err != nil {
+    return nil, err
+  }
+
+  return func() {
+    setTermios(fd, original)
+  }, nil
+}
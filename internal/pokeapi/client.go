@@ -0,0 +1,359 @@
+package pokeapi
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+  "os"
+  "path"
+  "path/filepath"
+  "sync"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokecache"
+)
+
+const defaultBaseURL = "https://pokeapi.co/api/v2"
+
+const defaultTimeout = 20 * time.Second
+
+// ErrOffline is returned when the client is offline and neither the cache nor the fixture directory has the url
+var ErrOffline = errors.New("not available offline")
+
+// the fetch layer returns these, wrapped with the url, whenever PokeAPI doesn't answer with a 2xx
+var (
+  ErrNotFound = errors.New("not found")
+  ErrRateLimited = errors.New("rate limited by PokeAPI")
+  ErrUpstream = errors.New("PokeAPI error")
+)
+
+// Client talks to PokeAPI, keeping every response it gets in a pokecache.Cache
+type Client struct {
+  baseURL string
+  httpClient http.Client
+  cache *pokecache.Cache
+
+  // offline never touches the network, only the cache and fixtureDir are used
+  offline bool
+  fixtureDir string
+
+  maxAttempts int
+  retryDelay time.Duration
+
+  // inflight has the downloads that are happening right now, so callers asking for the same url share one
+  inflightMu sync.Mutex
+  inflight map[string]*call
+}
+
+// call is one download shared by everyone who asked for its url while it was running
+type call struct {
+  done chan struct{}
+  body []byte
+  err error
+
+  // the download is only cancelled once every waiter has given up on it
+  waiters int
+  cancel context.CancelFunc
+}
+
+// Option changes how NewClient sets up the client
+type Option func(*Client)
+
+// WithBaseURL points the client at another PokeAPI, e.g. an httptest server
+func WithBaseURL(baseURL string) Option {
+  return func(c *Client) {
+    c.baseURL = baseURL
+  }
+}
+
+func WithTimeout(timeout time.Duration) Option {
+  return func(c *Client) {
+    c.httpClient.Timeout = timeout
+  }
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+  return func(c *Client) {
+    c.httpClient.Transport = transport
+  }
+}
+
+// WithMaxAttempts is how many times a request is tried before its failure is returned, 1 turns retries off
+func WithMaxAttempts(maxAttempts int) Option {
+  return func(c *Client) {
+    c.maxAttempts = maxAttempts
+  }
+}
+
+// WithRetryDelay is the backoff before the first retry, it doubles with every retry after that
+func WithRetryDelay(delay time.Duration) Option {
+  return func(c *Client) {
+    c.retryDelay = delay
+  }
+}
+
+func WithOffline(offline bool) Option {
+  return func(c *Client) {
+    c.offline = offline
+  }
+}
+
+// WithFixtureDir serves saved PokeAPI json from dir while offline, see fixturePath for the layout
+func WithFixtureDir(dir string) Option {
+  return func(c *Client) {
+    c.fixtureDir = dir
+  }
+}
+
+func (c *Client) Offline() bool {
+  return c.offline
+}
+
+func (c *Client) SetOffline(offline bool) {
+  c.offline = offline
+}
+
+func NewClient(cache *pokecache.Cache, opts ...Option) *Client {
+
+  client := &Client {
+    baseURL: defaultBaseURL,
+    httpClient: http.Client{
+      Timeout: defaultTimeout,
+    },
+    cache: cache,
+    inflight: make(map[string]*call),
+    maxAttempts: defaultMaxAttempts,
+    retryDelay: defaultRetryDelay,
+  }
+
+  for _, opt := range opts {
+    opt(client)
+  }
+
+  transport := client.httpClient.Transport
+  if transport == nil {
+    transport = http.DefaultTransport
+  }
+  client.httpClient.Transport = &retryTransport{
+    base: transport,
+    maxAttempts: client.maxAttempts,
+    baseDelay: client.retryDelay,
+  }
+
+  return client
+
+}
+
+// get decodes the json at url into v, only going to the network when the cache doesn't have it
+func (c *Client) get(ctx context.Context, url string, v any) error {
+
+  if cachedData, found := c.cache.Get(url); found {
+    decoder := json.NewDecoder(bytes.NewReader(cachedData))
+    err_decode := decoder.Decode(v)
+
+    if err_decode == nil {
+      return nil
+    }
+
+    // older versions cached error bodies, drop them and fetch the real thing
+    c.cache.Delete(url)
+  }
+
+  if c.offline {
+    // stale data beats no data when there's no network to refresh it from
+    if staleData, found := c.cache.GetAny(url); found && json.Unmarshal(staleData, v) == nil {
+      return nil
+    }
+    return c.getFixture(url, v)
+  }
+
+  body, err := c.fetch(ctx, url)
+  if err != nil {
+    return err
+  }
+
+  decoder := json.NewDecoder(bytes.NewReader(body))
+  err_decode := decoder.Decode(v)
+
+  if err_decode != nil {
+    return fmt.Errorf("error decoding json %w", err_decode)
+  }
+
+  return nil
+
+}
+
+// fetch downloads url, joining the download already running for it if there is one
+func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
+  c.inflightMu.Lock()
+  shared, found := c.inflight[url]
+  if !found {
+    // the download outlives whoever started it as long as someone else is still waiting on it
+    downloadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+    shared = &call{done: make(chan struct{}), cancel: cancel}
+    c.inflight[url] = shared
+
+    go func() {
+      shared.body, shared.err = c.download(downloadCtx, url)
+
+      c.inflightMu.Lock()
+      if c.inflight[url] == shared {
+        delete(c.inflight, url)
+      }
+      c.inflightMu.Unlock()
+
+      cancel()
+      close(shared.done)
+    }()
+  }
+  shared.waiters++
+  c.inflightMu.Unlock()
+
+  select {
+  case <- shared.done:
+    return shared.body, shared.err
+  case <- ctx.Done():
+    c.inflightMu.Lock()
+    shared.waiters--
+    if shared.waiters == 0 {
+      shared.cancel()
+      // anyone asking after this starts a new download rather than joining a cancelled one
+      if c.inflight[url] == shared {
+        delete(c.inflight, url)
+      }
+    }
+    c.inflightMu.Unlock()
+
+    return nil, ctx.Err()
+  }
+}
+
+// download does the actual GET, caching the body when it's a success.
+// When the cache still has an expired copy with validators the GET is conditional, and a 304 reuses that copy
+func (c *Client) download(ctx context.Context, url string) ([]byte, error) {
+  req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+  if err != nil {
+    return nil, fmt.Errorf("error creating a GET request %w", err)
+  }
+
+  stale, validators, hasStale := c.cache.GetStale(url)
+  if hasStale {
+    if validators.ETag != "" {
+      req.Header.Set("If-None-Match", validators.ETag)
+    }
+    if validators.LastModified != "" {
+      req.Header.Set("If-Modified-Since", validators.LastModified)
+    }
+  }
+
+  res, err := c.httpClient.Do(req)
+  if err != nil {
+    return nil, fmt.Errorf("error getting a response %w", err)
+  }
+
+  defer res.Body.Close()
+
+  if res.StatusCode == http.StatusNotModified && hasStale {
+    // adding it again starts its expiry over, keeping any validators the 304 updated
+    c.cache.AddWithValidators(url, stale, mergeValidators(validators, responseValidators(res)))
+    return stale, nil
+  }
+
+  if err := statusError(res, url); err != nil {
+    return nil, err
+  }
+
+  body, err := io.ReadAll(res.Body)
+  if err != nil {
+    return nil, fmt.Errorf("Error in converting response's body to a slice of bytes %w", err)
+  }
+
+  c.cache.AddWithValidators(url, body, responseValidators(res))
+
+  return body, nil
+}
+
+func responseValidators(res *http.Response) pokecache.Validators {
+  return pokecache.Validators{
+    ETag: res.Header.Get("ETag"),
+    LastModified: res.Header.Get("Last-Modified"),
+  }
+}
+
+// mergeValidators prefers what the server just sent, falling back to what was stored
+func mergeValidators(stored, fresh pokecache.Validators) pokecache.Validators {
+  if fresh.ETag == "" {
+    fresh.ETag = stored.ETag
+  }
+  if fresh.LastModified == "" {
+    fresh.LastModified = stored.LastModified
+  }
+
+  return fresh
+}
+
+// statusError maps a non-2xx response to one of the typed errors, error bodies are never cached
+func statusError(res *http.Response, url string) error {
+  switch {
+  case res.StatusCode >= 200 && res.StatusCode < 300:
+    return nil
+  case res.StatusCode == http.StatusNotFound:
+    return fmt.Errorf("%w: %s", ErrNotFound, url)
+  case res.StatusCode == http.StatusTooManyRequests:
+    return fmt.Errorf("%w: %s", ErrRateLimited, url)
+  default:
+    return fmt.Errorf("%w: %s returned %s", ErrUpstream, url, res.Status)
+  }
+}
+
+// fixturePath mirrors the url path inside fixtureDir the same way PokeAPI's api-data repo does,
+// so https://pokeapi.co/api/v2/pokemon/pikachu is {dir}/api/v2/pokemon/pikachu/index.json.
+// Paged listings keep their query in the file name, e.g. {dir}/api/v2/location-area/index_offset%3D20%26limit%3D20.json
+func (c *Client) fixturePath(rawURL string) (string, error) {
+  parsedURL, err := url.Parse(rawURL)
+  if err != nil {
+    return "", err
+  }
+
+  fileName := "index.json"
+  if parsedURL.RawQuery != "" {
+    fileName = "index_" + url.QueryEscape(parsedURL.RawQuery) + ".json"
+  }
+
+  // cleaning from the root keeps names like ../../etc inside fixtureDir
+  urlPath := path.Clean("/" + parsedURL.Path)
+
+  return filepath.Join(c.fixtureDir, filepath.FromSlash(urlPath), fileName), nil
+}
+
+func (c *Client) getFixture(rawURL string, v any) error {
+  if c.fixtureDir == "" {
+    return fmt.Errorf("%w: %s", ErrOffline, rawURL)
+  }
+
+  fixturePath, err := c.fixturePath(rawURL)
+  if err != nil {
+    return fmt.Errorf("%w: %s", ErrOffline, rawURL)
+  }
+
+  body, err := os.ReadFile(fixturePath)
+  if errors.Is(err, os.ErrNotExist) {
+    return fmt.Errorf("%w: %s", ErrOffline, rawURL)
+  }
+  if err != nil {
+    return fmt.Errorf("error reading fixture %w", err)
+  }
+
+  c.cache.Add(rawURL, body)
+
+  if err := json.Unmarshal(body, v); err != nil {
+    return fmt.Errorf("error decoding fixture %s %w", fixturePath, err)
+  }
+
+  return nil
+}
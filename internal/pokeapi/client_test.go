@@ -0,0 +1,384 @@
+package pokeapi
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "sync"
+  "testing"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokecache"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, *int) {
+  requests := 0
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    requests++
+    handler(w, r)
+  }))
+  t.Cleanup(server.Close)
+
+  cache := pokecache.NewCache(time.Minute)
+  t.Cleanup(cache.Close)
+
+  client := NewClient(cache, append([]Option{WithBaseURL(server.URL)}, opts...)...)
+  return client, &requests
+}
+
+func TestListLocationAreas(t *testing.T) {
+  var serverURL string
+  client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/location-area/" {
+      t.Errorf("unexpected path %s", r.URL.Path)
+    }
+    fmt.Fprintf(w, `{"count": 2, "next": "%s/location-area/?offset=1", "previous": null, "results": [{"name": "canalave-city-area"}]}`, serverURL)
+  })
+  serverURL = client.baseURL
+
+  page, err := client.ListLocationAreas(context.Background(), client.LocationAreasURL())
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  if len(page.Results) != 1 || page.Results[0].Name != "canalave-city-area" {
+    t.Errorf("unexpected results %+v", page.Results)
+  }
+  if page.Next == nil || *page.Next != serverURL + "/location-area/?offset=1" {
+    t.Errorf("expected next link to be decoded")
+  }
+  if page.Previous != nil {
+    t.Errorf("expected no previous link on the first page")
+  }
+}
+
+func TestGetLocationArea(t *testing.T) {
+  client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/location-area/canalave-city-area" {
+      t.Errorf("unexpected path %s", r.URL.Path)
+    }
+    fmt.Fprint(w, `{"name": "canalave-city-area", "pokemon_encounters": [{"pokemon": {"name": "tentacool"}}, {"pokemon": {"name": "wingull"}}]}`)
+  })
+
+  area, err := client.GetLocationArea(context.Background(), "canalave-city-area")
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  if len(area.PokemonEncounters) != 2 || area.PokemonEncounters[1].Pokemon.Name != "wingull" {
+    t.Errorf("unexpected encounters %+v", area.PokemonEncounters)
+  }
+}
+
+func TestGetPokemonUsesCache(t *testing.T) {
+  client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    fmt.Fprint(w, `{"name": "pikachu", "base_experience": 112, "height": 4, "weight": 60}`)
+  })
+
+  for i := 0; i < 3; i++ {
+    pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+    if err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+    if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+      t.Errorf("unexpected pokemon %+v", pokemon)
+    }
+  }
+
+  if *requests != 1 {
+    t.Errorf("expected 1 request to the server, got %d", *requests)
+  }
+}
+
+func TestErrorStatuses(t *testing.T) {
+  cases := []struct {
+    status int
+    expected error
+  }{
+    {status: http.StatusNotFound, expected: ErrNotFound},
+    {status: http.StatusTooManyRequests, expected: ErrRateLimited},
+    {status: http.StatusBadGateway, expected: ErrUpstream},
+  }
+
+  for _, c := range cases {
+    t.Run(http.StatusText(c.status), func(t *testing.T) {
+      client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        http.Error(w, "Not Found", c.status)
+      }, WithMaxAttempts(1))
+
+      for i := 0; i < 2; i++ {
+        _, err := client.GetPokemon(context.Background(), "pikachuu")
+        if !errors.Is(err, c.expected) {
+          t.Errorf("expected %v, got %v", c.expected, err)
+        }
+      }
+
+      // error bodies aren't cached, so both calls went to the server
+      if *requests != 2 {
+        t.Errorf("expected 2 requests, got %d", *requests)
+      }
+      if client.cache.Len() != 0 {
+        t.Errorf("expected nothing cached, got %v", client.cache.Keys())
+      }
+    })
+  }
+}
+
+func TestOfflineServesFixtures(t *testing.T) {
+  fixtureDir := t.TempDir()
+  pokemonDir := filepath.Join(fixtureDir, "api", "v2", "pokemon", "pikachu")
+  if err := os.MkdirAll(pokemonDir, 0o755); err != nil {
+    t.Fatal(err)
+  }
+  if err := os.WriteFile(filepath.Join(pokemonDir, "index.json"), []byte(`{"name": "pikachu", "base_experience": 112}`), 0o644); err != nil {
+    t.Fatal(err)
+  }
+
+  cache := pokecache.NewCache(time.Minute)
+  t.Cleanup(cache.Close)
+
+  // nothing listens on this base url, so any network access would fail the test
+  client := NewClient(cache, WithBaseURL("http://127.0.0.1:1/api/v2"), WithOffline(true), WithFixtureDir(fixtureDir))
+
+  pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if pokemon.BaseExperience != 112 {
+    t.Errorf("unexpected pokemon %+v", pokemon)
+  }
+
+  _, err = client.GetPokemon(context.Background(), "mewtwo")
+  if !errors.Is(err, ErrOffline) {
+    t.Errorf("expected ErrOffline, got %v", err)
+  }
+}
+
+func TestOfflineServesCache(t *testing.T) {
+  client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    fmt.Fprint(w, `{"name": "pikachu"}`)
+  })
+
+  if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  client.SetOffline(true)
+
+  if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+    t.Errorf("expected the cached pokemon while offline, got %v", err)
+  }
+  if _, err := client.GetLocationArea(context.Background(), "canalave-city-area"); !errors.Is(err, ErrOffline) {
+    t.Errorf("expected ErrOffline, got %v", err)
+  }
+  if *requests != 1 {
+    t.Errorf("expected 1 request to the server, got %d", *requests)
+  }
+}
+
+func TestOfflineServesExpiredCache(t *testing.T) {
+  const ttl = 5 * time.Millisecond
+  dir := t.TempDir()
+
+  first := pokecache.NewCache(ttl, pokecache.WithDiskStore(dir, ttl))
+  first.Add("http://127.0.0.1:1/api/v2/pokemon/pikachu", []byte(`{"name": "pikachu", "base_experience": 112}`))
+  first.Close()
+
+  time.Sleep(ttl * 3)
+
+  // a later session, the entry is only on disk and long past its ttl
+  cache := pokecache.NewCache(ttl, pokecache.WithDiskStore(dir, ttl))
+  t.Cleanup(cache.Close)
+  client := NewClient(cache, WithBaseURL("http://127.0.0.1:1/api/v2"), WithOffline(true))
+
+  pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+  if err != nil {
+    t.Fatalf("expected the expired pokemon while offline, got %v", err)
+  }
+  if pokemon.BaseExperience != 112 {
+    t.Errorf("unexpected pokemon %+v", pokemon)
+  }
+}
+
+func TestCancelInFlightRequest(t *testing.T) {
+  client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    <- r.Context().Done()
+  })
+
+  ctx, cancel := context.WithCancel(context.Background())
+  time.AfterFunc(10 * time.Millisecond, cancel)
+
+  _, err := client.GetPokemon(ctx, "pikachu")
+  if !errors.Is(err, context.Canceled) {
+    t.Errorf("expected context.Canceled, got %v", err)
+  }
+}
+
+// waitForWaiters blocks until n callers are waiting on the download of url
+func waitForWaiters(t *testing.T, client *Client, url string, n int) {
+  deadline := time.Now().Add(5 * time.Second)
+  for time.Now().Before(deadline) {
+    client.inflightMu.Lock()
+    shared, found := client.inflight[url]
+    waiting := found && shared.waiters == n
+    client.inflightMu.Unlock()
+    if waiting {
+      return
+    }
+    time.Sleep(time.Millisecond)
+  }
+  t.Fatalf("expected %d callers waiting on %s", n, url)
+}
+
+func TestConcurrentRequestsAreCoalesced(t *testing.T) {
+  release := make(chan struct{})
+  client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    <- release
+    fmt.Fprint(w, `{"name": "pikachu"}`)
+  })
+
+  const callers = 10
+  var wg sync.WaitGroup
+  errs := make(chan error, callers)
+  for i := 0; i < callers; i++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+      if err == nil && pokemon.Name != "pikachu" {
+        err = fmt.Errorf("unexpected pokemon %+v", pokemon)
+      }
+      errs <- err
+    }()
+  }
+
+  waitForWaiters(t, client, client.baseURL + "/pokemon/pikachu", callers)
+  close(release)
+  wg.Wait()
+  close(errs)
+
+  for err := range errs {
+    if err != nil {
+      t.Errorf("unexpected error: %v", err)
+    }
+  }
+  if *requests != 1 {
+    t.Errorf("expected 1 request to the server, got %d", *requests)
+  }
+}
+
+func TestCancellingOneCallerKeepsTheSharedRequest(t *testing.T) {
+  release := make(chan struct{})
+  client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    select {
+    case <- release:
+      fmt.Fprint(w, `{"name": "pikachu"}`)
+    case <- r.Context().Done():
+    }
+  })
+
+  ctx, cancel := context.WithCancel(context.Background())
+  cancelledErr := make(chan error, 1)
+  go func() {
+    _, err := client.GetPokemon(ctx, "pikachu")
+    cancelledErr <- err
+  }()
+
+  url := client.baseURL + "/pokemon/pikachu"
+  waitForWaiters(t, client, url, 1)
+
+  pokemonErr := make(chan error, 1)
+  go func() {
+    _, err := client.GetPokemon(context.Background(), "pikachu")
+    pokemonErr <- err
+  }()
+  waitForWaiters(t, client, url, 2)
+
+  cancel()
+  if err := <- cancelledErr; !errors.Is(err, context.Canceled) {
+    t.Errorf("expected context.Canceled, got %v", err)
+  }
+
+  close(release)
+  if err := <- pokemonErr; err != nil {
+    t.Errorf("expected the other caller to still get pikachu, got %v", err)
+  }
+  if *requests != 1 {
+    t.Errorf("expected 1 request to the server, got %d", *requests)
+  }
+}
+
+func TestExpiredEntriesAreRevalidated(t *testing.T) {
+  const interval = 5 * time.Millisecond
+  requests, notModified := 0, 0
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    requests++
+    if r.Header.Get("If-None-Match") == `"v1"` {
+      notModified++
+      w.WriteHeader(http.StatusNotModified)
+      return
+    }
+    w.Header().Set("ETag", `"v1"`)
+    fmt.Fprint(w, `{"name": "pikachu"}`)
+  }))
+  t.Cleanup(server.Close)
+
+  cache := pokecache.NewCache(interval)
+  t.Cleanup(cache.Close)
+  client := NewClient(cache, WithBaseURL(server.URL))
+
+  for i := 0; i < 3; i++ {
+    pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+    if err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+    if pokemon.Name != "pikachu" {
+      t.Errorf("unexpected pokemon %+v", pokemon)
+    }
+    time.Sleep(interval * 2)
+  }
+
+  // one full download, then a 304 every time the entry had expired
+  if requests != 3 || notModified != 2 {
+    t.Errorf("expected 3 requests with 2 of them 304s, got %d and %d", requests, notModified)
+  }
+
+  if _, found := cache.Get(server.URL + "/pokemon/pikachu"); found {
+    t.Errorf("expected the entry to have expired again")
+  }
+  if _, validators, found := cache.GetStale(server.URL + "/pokemon/pikachu"); !found || validators.ETag != `"v1"` {
+    t.Errorf("expected the ETag to be kept, got %+v", validators)
+  }
+}
+
+func TestGetPokemonSpeciesFromSpeciesURL(t *testing.T) {
+  var serverURL string
+  client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+    switch r.URL.Path {
+    case "/pokemon/pikachu":
+      fmt.Fprintf(w, `{"name": "pikachu", "species": {"name": "pikachu", "url": "%s/pokemon-species/25/"}}`, serverURL)
+    case "/pokemon-species/25/":
+      fmt.Fprint(w, `{"id": 25, "name": "pikachu", "capture_rate": 190, "gender_rate": 4}`)
+    default:
+      http.NotFound(w, r)
+    }
+  })
+  serverURL = client.baseURL
+
+  pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  species, err := client.GetPokemonSpecies(context.Background(), pokemon.Species.URL)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if species.Name != "pikachu" || species.CaptureRate != 190 || species.GenderRate != 4 {
+    t.Errorf("unexpected species %+v", species)
+  }
+}
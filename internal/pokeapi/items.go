@@ -0,0 +1,14 @@
+package pokeapi
+
+import (
+  "context"
+)
+
+func (c *Client) GetItem(ctx context.Context, name string) (Item, error) {
+  var item Item
+  if err := c.get(ctx, c.baseURL + "/item/" + name, &item); err != nil {
+    return Item{}, err
+  }
+
+  return item, nil
+}
@@ -0,0 +1,30 @@
+package pokeapi
+
+import (
+  "context"
+)
+
+// LocationAreasURL is the url of the first page of the location-area listing
+func (c *Client) LocationAreasURL() string {
+  return c.baseURL + "/location-area/"
+}
+
+// ListLocationAreas gets the page of location areas at pageURL,
+// which is either LocationAreasURL or a Next/Previous link from an earlier page
+func (c *Client) ListLocationAreas(ctx context.Context, pageURL string) (LocationAreaList, error) {
+  var locationAreas LocationAreaList
+  if err := c.get(ctx, pageURL, &locationAreas); err != nil {
+    return LocationAreaList{}, err
+  }
+
+  return locationAreas, nil
+}
+
+func (c *Client) GetLocationArea(ctx context.Context, name string) (LocationArea, error) {
+  var locationArea LocationArea
+  if err := c.get(ctx, c.baseURL + "/location-area/" + name, &locationArea); err != nil {
+    return LocationArea{}, err
+  }
+
+  return locationArea, nil
+}
@@ -0,0 +1,38 @@
+package pokeapi
+
+import (
+  "context"
+  "fmt"
+)
+
+// nameIndexLimit is bigger than any PokeAPI listing, so one request gets every name
+const nameIndexLimit = 100000
+
+// PokemonNames is the name of every Pokemon PokeAPI knows about
+func (c *Client) PokemonNames(ctx context.Context) ([]string, error) {
+  return c.listNames(ctx, "/pokemon")
+}
+
+// LocationAreaNames is the name of every location area PokeAPI knows about
+func (c *Client) LocationAreaNames(ctx context.Context) ([]string, error) {
+  return c.listNames(ctx, "/location-area")
+}
+
+// listNames gets a whole listing in one page, it goes through get so it's cached like everything else
+func (c *Client) listNames(ctx context.Context, path string) ([]string, error) {
+  var list struct {
+    Results []struct {
+      Name string `json:"name"`
+    } `json:"results"`
+  }
+  if err := c.get(ctx, fmt.Sprintf("%s%s?limit=%d", c.baseURL, path, nameIndexLimit), &list); err != nil {
+    return nil, err
+  }
+
+  names := make([]string, 0, len(list.Results))
+  for _, result := range list.Results {
+    names = append(names, result.Name)
+  }
+
+  return names, nil
+}
@@ -0,0 +1,29 @@
+package pokeapi
+
+import (
+  "context"
+)
+
+func (c *Client) GetPokemon(ctx context.Context, name string) (Pokemon, error) {
+  var pokemon Pokemon
+  if err := c.get(ctx, c.baseURL + "/pokemon/" + name, &pokemon); err != nil {
+    return Pokemon{}, err
+  }
+
+  return pokemon, nil
+}
+
+// PokemonSpeciesURL is the url of the species called name, the same url a Pokemon's Species.URL links to
+func (c *Client) PokemonSpeciesURL(name string) string {
+  return c.baseURL + "/pokemon-species/" + name
+}
+
+// GetPokemonSpecies gets the species at speciesURL, which is normally a Pokemon's Species.URL
+func (c *Client) GetPokemonSpecies(ctx context.Context, speciesURL string) (PokemonSpecies, error) {
+  var species PokemonSpecies
+  if err := c.get(ctx, speciesURL, &species); err != nil {
+    return PokemonSpecies{}, err
+  }
+
+  return species, nil
+}
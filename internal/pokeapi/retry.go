@@ -0,0 +1,95 @@
+package pokeapi
+
+import (
+  "io"
+  "math/rand"
+  "net/http"
+  "strconv"
+  "time"
+)
+
+const (
+  defaultMaxAttempts = 3
+  defaultRetryDelay = 500 * time.Millisecond
+  maxRetryDelay = 10 * time.Second
+)
+
+// retryTransport retries GETs that failed on the network or came back 429 or 5xx,
+// waiting a jittered exponential backoff in between unless the server sent Retry-After
+type retryTransport struct {
+  base http.RoundTripper
+  maxAttempts int
+  baseDelay time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+  for attempt := 1; ; attempt++ {
+    res, err := t.base.RoundTrip(req)
+
+    // anything with a body can't be sent twice, and PokeAPI only ever gets GETs anyway
+    retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+    if attempt >= t.maxAttempts || !retryable || !shouldRetry(res, err) || req.Context().Err() != nil {
+      return res, err
+    }
+
+    delay := t.backoff(attempt)
+    if res != nil {
+      if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+        delay = min(retryAfter, maxRetryDelay)
+      }
+
+      io.Copy(io.Discard, res.Body)
+      res.Body.Close()
+    }
+
+    timer := time.NewTimer(delay)
+    select {
+    case <- req.Context().Done():
+      timer.Stop()
+      return nil, req.Context().Err()
+    case <- timer.C:
+    }
+  }
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+  if err != nil {
+    return true
+  }
+
+  return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// backoff doubles the delay every attempt and picks somewhere in its upper half,
+// so lots of clients failing at once don't all come back at the same moment
+func (t *retryTransport) backoff(attempt int) time.Duration {
+  delay := t.baseDelay << (attempt - 1)
+  if delay <= 0 || delay > maxRetryDelay {
+    delay = maxRetryDelay
+  }
+
+  half := int64(delay / 2)
+  if half == 0 {
+    return delay
+  }
+
+  // the jitter only spreads retries out, it doesn't need the game's seeded random source
+  return time.Duration(half + rand.Int63n(half + 1))
+}
+
+// parseRetryAfter understands both forms of Retry-After, a number of seconds or an http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+  if value == "" {
+    return 0, false
+  }
+
+  if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+    return time.Duration(seconds) * time.Second, true
+  }
+
+  if date, err := http.ParseTime(value); err == nil {
+    return max(time.Until(date), 0), true
+  }
+
+  return 0, false
+}
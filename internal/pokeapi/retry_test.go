@@ -0,0 +1,117 @@
+package pokeapi
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokecache"
+)
+
+// failingServer answers the first failures requests with status and every one after that with a pokemon
+func failingServer(t *testing.T, failures int, status int, retryAfter string) (*httptest.Server, *int) {
+  requests := 0
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    requests++
+    if requests <= failures {
+      if retryAfter != "" {
+        w.Header().Set("Retry-After", retryAfter)
+      }
+      w.WriteHeader(status)
+      return
+    }
+    fmt.Fprint(w, `{"name": "pikachu"}`)
+  }))
+  t.Cleanup(server.Close)
+
+  return server, &requests
+}
+
+func newRetryClient(t *testing.T, baseURL string, maxAttempts int) *Client {
+  cache := pokecache.NewCache(time.Minute)
+  t.Cleanup(cache.Close)
+
+  return NewClient(cache, WithBaseURL(baseURL), WithMaxAttempts(maxAttempts), WithRetryDelay(time.Millisecond))
+}
+
+func TestRetries(t *testing.T) {
+  cases := []struct {
+    name string
+    failures int
+    status int
+    retryAfter string
+    expectedRequests int
+  }{
+    {
+      name: "5xx is retried",
+      failures: 2,
+      status: http.StatusServiceUnavailable,
+      expectedRequests: 3,
+    },
+    {
+      name: "429 honours Retry-After",
+      failures: 1,
+      status: http.StatusTooManyRequests,
+      retryAfter: "0",
+      expectedRequests: 2,
+    },
+  }
+
+  for _, c := range cases {
+    t.Run(c.name, func(t *testing.T) {
+      server, requests := failingServer(t, c.failures, c.status, c.retryAfter)
+      client := newRetryClient(t, server.URL, 3)
+
+      pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+      if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+      }
+      if pokemon.Name != "pikachu" {
+        t.Errorf("unexpected pokemon %+v", pokemon)
+      }
+      if *requests != c.expectedRequests {
+        t.Errorf("expected %d requests, got %d", c.expectedRequests, *requests)
+      }
+    })
+  }
+}
+
+func TestRetriesGiveUp(t *testing.T) {
+  server, requests := failingServer(t, 10, http.StatusInternalServerError, "")
+  client := newRetryClient(t, server.URL, 3)
+
+  client.GetPokemon(context.Background(), "pikachu")
+
+  if *requests != 3 {
+    t.Errorf("expected to give up after 3 requests, got %d", *requests)
+  }
+}
+
+func TestNotFoundIsNotRetried(t *testing.T) {
+  server, requests := failingServer(t, 10, http.StatusNotFound, "")
+  client := newRetryClient(t, server.URL, 3)
+
+  client.GetPokemon(context.Background(), "pikachu")
+
+  if *requests != 1 {
+    t.Errorf("expected 1 request, got %d", *requests)
+  }
+}
+
+func TestParseRetryAfter(t *testing.T) {
+  if delay, ok := parseRetryAfter("2"); !ok || delay != 2 * time.Second {
+    t.Errorf("expected 2s, got %v %v", delay, ok)
+  }
+
+  date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+  if delay, ok := parseRetryAfter(date); !ok || delay < 59 * time.Minute {
+    t.Errorf("expected about an hour, got %v %v", delay, ok)
+  }
+
+  if _, ok := parseRetryAfter("soon"); ok {
+    t.Errorf("expected garbage to be ignored")
+  }
+}
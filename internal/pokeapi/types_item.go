@@ -0,0 +1,19 @@
+package pokeapi
+
+// Item is the /item/{name} resource, Cost is what it sells for in a Poke Mart, 0 when it isn't sold
+type Item struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Cost     int    `json:"cost"`
+	Category struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"category"`
+	Names []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+}
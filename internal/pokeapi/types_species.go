@@ -0,0 +1,45 @@
+package pokeapi
+
+// PokemonSpecies is the /pokemon-species/{name} resource, what all forms of a Pokemon have in common
+type PokemonSpecies struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Order              int    `json:"order"`
+	CaptureRate        int    `json:"capture_rate"`
+	BaseHappiness      int    `json:"base_happiness"`
+	GenderRate         int    `json:"gender_rate"`
+	HatchCounter       int    `json:"hatch_counter"`
+	IsBaby             bool   `json:"is_baby"`
+	IsLegendary        bool   `json:"is_legendary"`
+	IsMythical         bool   `json:"is_mythical"`
+	EvolvesFromSpecies *struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"evolves_from_species"`
+	GrowthRate struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"growth_rate"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	Genera []struct {
+		Genus    string `json:"genus"`
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"genera"`
+	FlavorTextEntries []struct {
+		FlavorText string `json:"flavor_text"`
+		Language   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Version struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version"`
+	} `json:"flavor_text_entries"`
+}
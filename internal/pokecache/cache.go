@@ -1,26 +1,109 @@
 package pokecache
 
 import (
+  "container/list"
+  "sort"
   "sync"
   "time"
 )
 
 type Cache struct {
-  data map[string]cacheValue
+  data map[string]*list.Element
   mu sync.Mutex 
   interval time.Duration
+  disk *diskStore
+
+  // lru has the most recently used entry at the front, every element holds a *cacheValue
+  lru *list.List
+  size int
+  maxEntries int
+  maxBytes int
+
+  // done stops reapLoop, once closed the cache stays empty for good
+  done chan struct{}
+  closeOnce sync.Once
+  closed bool
+
+  hits int
+  misses int
+  evictions int
+  expirations int
+}
+
+// Stats is a snapshot of what the cache holds and how well it's doing
+type Stats struct {
+  Entries int
+  Bytes int
+  Hits int
+  Misses int
+  // Evictions are entries pushed out by the size limits, Expirations ones that outlived the interval
+  Evictions int
+  Expirations int
+  OldestAge time.Duration
 }
 
 type cacheValue struct {
+  key string
   createdAt time.Time
   val []byte
+  validators Validators
+}
+
+// Validators are what the server sent to tell whether val is still current. Entries that have them
+// are kept once they expire, so GetStale can hand them back for a conditional request
+type Validators struct {
+  ETag string
+  LastModified string
+}
+
+func (v Validators) empty() bool {
+  return v.ETag == "" && v.LastModified == ""
+}
+
+// size is roughly how much memory an entry holds on to
+func (v *cacheValue) size() int {
+  return len(v.key) + len(v.val)
+}
+
+// Option changes how NewCache sets up the cache
+type Option func(*Cache)
+
+// WithDiskStore also keeps every entry as a file in dir, so it's still there after a restart.
+// Entries on disk expire after ttl instead of the in-memory interval, a ttl of 0 keeps them forever
+func WithDiskStore(dir string, ttl time.Duration) Option {
+  return func(c *Cache) {
+    c.disk = &diskStore{
+      dir: dir,
+      ttl: ttl,
+    }
+  }
 }
 
-func NewCache(interval time.Duration) *Cache {
+// WithMaxEntries keeps at most n entries in memory, evicting the least recently used ones first
+func WithMaxEntries(n int) Option {
+  return func(c *Cache) {
+    c.maxEntries = n
+  }
+}
+
+// WithMaxBytes keeps the keys and values held in memory under n bytes, evicting the least recently used ones first
+func WithMaxBytes(n int) Option {
+  return func(c *Cache) {
+    c.maxBytes = n
+  }
+}
+
+func NewCache(interval time.Duration, opts ...Option) *Cache {
 
   cache := &Cache {
-    data: make(map[string]cacheValue),
+    data: make(map[string]*list.Element),
     interval: interval,
+    lru: list.New(),
+    done: make(chan struct{}),
+  }
+
+  for _, opt := range opts {
+    opt(cache)
   }
   
   go cache.reapLoop()
@@ -29,13 +112,26 @@ func NewCache(interval time.Duration) *Cache {
 }
 
 func (c *Cache) Add(key string, val []byte) {
+  c.AddWithValidators(key, val, Validators{})
+}
+
+// AddWithValidators is Add for a response that came with an ETag or Last-Modified.
+// Adding a key again, e.g. after a 304, starts its expiry over
+func (c *Cache) AddWithValidators(key string, val []byte, validators Validators) {
 
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  c.data[key] = cacheValue {
-    createdAt: time.Now(),
-    val: val,
+  if c.closed {
+    return
+  }
+
+  createdAt := time.Now()
+  c.store(key, val, validators, createdAt)
+
+  // the disk is only a second chance for later sessions, so failing to write it isn't fatal
+  if c.disk != nil {
+    c.disk.put(key, val, validators, createdAt)
   }
   
 }
@@ -45,14 +141,226 @@ func (c *Cache) Get(key string) ([]byte, bool) {
   c.mu.Lock()
   defer c.mu.Unlock()
 
-  cacheVal, found := c.data[key] 
-  if !found || time.Since(cacheVal.createdAt) > c.interval {
-    delete(c.data, key)
+  if c.closed {
+    return nil, false
+  }
+
+  element, found := c.data[key] 
+  if found {
+    cacheVal := element.Value.(*cacheValue)
+    if time.Since(cacheVal.createdAt) <= c.interval {
+      c.lru.MoveToFront(element)
+      c.hits++
+      return cacheVal.val, true
+    }
+
+    // validated entries stay around stale, see GetStale
+    if cacheVal.validators.empty() {
+      c.remove(element)
+      c.expirations++
+    }
+  }
+
+  if c.disk != nil {
+    if entry, found := c.disk.get(key); found && !c.disk.expired(entry) {
+      c.store(key, entry.Val, entry.validators(), time.Now())
+      c.hits++
+      return entry.Val, true
+    }
+  }
+
+  c.misses++
+  return nil, false
+
+}
+
+// GetStale returns an entry however old it is, as long as it has validators to revalidate it with.
+// It doesn't count as a hit or a miss
+func (c *Cache) GetStale(key string) ([]byte, Validators, bool) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  if c.closed {
+    return nil, Validators{}, false
+  }
+
+  if element, found := c.data[key]; found {
+    cacheVal := element.Value.(*cacheValue)
+    if !cacheVal.validators.empty() {
+      return cacheVal.val, cacheVal.validators, true
+    }
+  }
+
+  if c.disk != nil {
+    if entry, found := c.disk.get(key); found && !entry.validators().empty() {
+      return entry.Val, entry.validators(), true
+    }
+  }
+
+  return nil, Validators{}, false
+}
+
+// GetAny returns an entry however old it is, validators or not, for when there's no way to get a fresh one,
+// e.g. offline. It doesn't count as a hit or a miss either
+func (c *Cache) GetAny(key string) ([]byte, bool) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  if c.closed {
     return nil, false
   }
 
-  return cacheVal.val, true
+  if element, found := c.data[key]; found {
+    return element.Value.(*cacheValue).val, true
+  }
+
+  if c.disk != nil {
+    if entry, found := c.disk.get(key); found {
+      return entry.Val, true
+    }
+  }
+
+  return nil, false
+}
+
+// store puts the entry at the front of the lru and evicts from the back until the limits hold again.
+// c.mu must be held
+func (c *Cache) store(key string, val []byte, validators Validators, createdAt time.Time) {
+  if element, found := c.data[key]; found {
+    c.remove(element)
+  }
+
+  cacheVal := &cacheValue {
+    key: key,
+    createdAt: createdAt,
+    val: val,
+    validators: validators,
+  }
+  c.data[key] = c.lru.PushFront(cacheVal)
+  c.size += cacheVal.size()
+
+  for c.overLimit() {
+    c.remove(c.lru.Back())
+    c.evictions++
+  }
+}
+
+func (c *Cache) overLimit() bool {
+  if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+    return true
+  }
+
+  return c.maxBytes > 0 && c.size > c.maxBytes
+}
+
+// remove drops an entry from memory, c.mu must be held
+func (c *Cache) remove(element *list.Element) {
+  cacheVal := c.lru.Remove(element).(*cacheValue)
+  delete(c.data, cacheVal.key)
+  c.size -= cacheVal.size()
+}
+
+// Len is the number of entries held in memory
+func (c *Cache) Len() int {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  return c.lru.Len()
+}
+
+// Keys lists the keys held in memory in sorted order
+func (c *Cache) Keys() []string {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  keys := make([]string, 0, len(c.data))
+  for key := range c.data {
+    keys = append(keys, key)
+  }
+  sort.Strings(keys)
+
+  return keys
+}
+
+// Delete drops key from memory and from the disk store, it reports whether the key was in memory
+func (c *Cache) Delete(key string) bool {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  if c.closed {
+    return false
+  }
+
+  if c.disk != nil {
+    c.disk.delete(key)
+  }
+
+  element, found := c.data[key]
+  if !found {
+    return false
+  }
+
+  c.remove(element)
+  return true
+}
+
+// Clear drops every entry from memory and from the disk store, the stats counters keep going.
+// On a closed cache it does nothing, like Add and Delete
+func (c *Cache) Clear() error {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  if c.closed {
+    return nil
+  }
+
+  c.data = make(map[string]*list.Element)
+  c.lru.Init()
+  c.size = 0
+
+  if c.disk != nil {
+    return c.disk.clear()
+  }
+
+  return nil
+}
+
+func (c *Cache) Stats() Stats {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  stats := Stats {
+    Entries: c.lru.Len(),
+    Bytes: c.size,
+    Hits: c.hits,
+    Misses: c.misses,
+    Evictions: c.evictions,
+    Expirations: c.expirations,
+  }
+
+  for _, element := range c.data {
+    if age := time.Since(element.Value.(*cacheValue).createdAt); age > stats.OldestAge {
+      stats.OldestAge = age
+    }
+  }
+
+  return stats
+}
+
+// Close stops the reaper and drops everything held in memory, entries on disk are kept.
+// Add and Get on a closed cache do nothing, and closing it again is fine
+func (c *Cache) Close() {
+  c.closeOnce.Do(func() {
+    close(c.done)
 
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.closed = true
+    c.data = make(map[string]*list.Element)
+    c.lru.Init()
+    c.size = 0
+  })
 }
 
 func (c *Cache) reapLoop() {
@@ -60,11 +368,18 @@ func (c *Cache) reapLoop() {
   ticker := time.NewTicker(c.interval) 
   defer ticker.Stop()
   for {
-    <- ticker.C
+    select {
+    case <- c.done:
+      return
+    case <- ticker.C:
+    }
+
     c.mu.Lock()
-    for key, cacheValue := range c.data {
-      if time.Since(cacheValue.createdAt) > c.interval {
-        delete(c.data, key)
+    for _, element := range c.data {
+      cacheVal := element.Value.(*cacheValue)
+      if time.Since(cacheVal.createdAt) > c.interval && cacheVal.validators.empty() {
+        c.remove(element)
+        c.expirations++
       } 
     }
     c.mu.Unlock()
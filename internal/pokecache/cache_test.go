@@ -2,6 +2,8 @@ package pokecache
 
 import (
   "fmt"
+  "os"
+  "path/filepath"
   "testing"
   "time"
 )
@@ -42,6 +44,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -60,6 +63,7 @@ func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
 	cache := NewCache(baseTime)
+	defer cache.Close()
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -77,3 +81,205 @@ func TestReapLoop(t *testing.T) {
 	}
 }
 
+func TestDiskStoreSurvivesNewCache(t *testing.T) {
+	dir := t.TempDir()
+
+	first := NewCache(time.Minute, WithDiskStore(dir, time.Hour))
+	defer first.Close()
+	first.Add("https://example.com", []byte("testdata"))
+
+	second := NewCache(time.Minute, WithDiskStore(dir, time.Hour))
+	defer second.Close()
+	val, ok := second.Get("https://example.com")
+	if !ok {
+		t.Errorf("expected to find key on disk")
+		return
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value on disk")
+		return
+	}
+}
+
+func TestDiskStoreTTL(t *testing.T) {
+	const diskTTL = 5 * time.Millisecond
+	dir := t.TempDir()
+
+	first := NewCache(time.Minute, WithDiskStore(dir, diskTTL))
+	defer first.Close()
+	first.Add("https://example.com", []byte("testdata"))
+
+	time.Sleep(diskTTL + 5*time.Millisecond)
+
+	second := NewCache(time.Minute, WithDiskStore(dir, diskTTL))
+	defer second.Close()
+	_, ok := second.Get("https://example.com")
+	if ok {
+		t.Errorf("expected disk entry to have expired")
+		return
+	}
+}
+
+func TestMaxEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(time.Minute, WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+
+	// touching a makes b the least recently used entry
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("expected to find a")
+		return
+	}
+
+	cache.Add("c", []byte("3"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("expected to still find a")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Errorf("expected to find c")
+	}
+}
+
+func TestMaxBytesEvictsLeastRecentlyUsed(t *testing.T) {
+	// every entry below is a 1 byte key and a 4 byte value
+	cache := NewCache(time.Minute, WithMaxBytes(12))
+	defer cache.Close()
+	cache.Add("a", []byte("1111"))
+	cache.Add("b", []byte("2222"))
+	cache.Add("c", []byte("3333"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Errorf("expected to find c")
+	}
+
+	// an entry bigger than the whole limit is never kept in memory
+	cache.Add("huge", make([]byte, 100))
+	if _, ok := cache.Get("huge"); ok {
+		t.Errorf("expected huge to be evicted straight away")
+	}
+}
+
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(time.Minute, WithDiskStore(dir, 0))
+	cache.Add("https://example.com", []byte("testdata"))
+
+	cache.Close()
+	cache.Close()
+
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected a closed cache to be empty")
+	}
+
+	cache.Add("https://example.com", []byte("testdata"))
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected Add on a closed cache to do nothing")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	reopened := NewCache(time.Minute, WithDiskStore(dir, 0))
+	defer reopened.Close()
+	if _, ok := reopened.Get("https://example.com"); !ok {
+		t.Errorf("expected Clear on a closed cache to keep the disk entries")
+	}
+}
+
+func TestStatsAndManagement(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(time.Minute, WithDiskStore(dir, time.Hour))
+	defer cache.Close()
+
+	cache.Add("b", []byte("22"))
+	cache.Add("a", []byte("1"))
+	cache.Get("a")
+	cache.Get("missing")
+
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Len())
+	}
+
+	keys := cache.Keys()
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("expected sorted keys, got %v", keys)
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Bytes != 5 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if !cache.Delete("a") {
+		t.Errorf("expected a to be deleted")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be gone from memory and disk")
+	}
+
+	// the cache dir can be shared, only the cache's own files get cleared
+	for _, name := range []string{"settings.json", "entry-123.tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("unexpected error clearing: %v", err)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be gone from memory and disk")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "settings.json")); err != nil {
+		t.Errorf("expected a file that isn't an entry to be left alone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "entry-123.tmp")); !os.IsNotExist(err) {
+		t.Errorf("expected a leftover temp file to be cleared, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected an empty cache")
+	}
+}
+
+func TestStaleEntriesKeepValidators(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	cache := NewCache(interval, WithDiskStore(t.TempDir(), interval))
+	defer cache.Close()
+
+	validators := Validators{ETag: `"abc"`}
+	cache.AddWithValidators("https://example.com/validated", []byte("validated"), validators)
+	cache.Add("https://example.com/plain", []byte("plain"))
+
+	time.Sleep(interval * 3)
+
+	if _, ok := cache.Get("https://example.com/validated"); ok {
+		t.Errorf("expected validated entry to have expired")
+	}
+
+	val, stale, ok := cache.GetStale("https://example.com/validated")
+	if !ok || string(val) != "validated" || stale != validators {
+		t.Errorf("expected the stale entry with its validators, got %q %+v %v", val, stale, ok)
+	}
+
+	if _, _, ok := cache.GetStale("https://example.com/plain"); ok {
+		t.Errorf("expected entry without validators to not be stale")
+	}
+	// it's still on disk though, for when there's nothing fresher
+	if val, ok := cache.GetAny("https://example.com/plain"); !ok || string(val) != "plain" {
+		t.Errorf("expected the expired entry from disk, got %q %v", val, ok)
+	}
+
+	// adding it again, like a 304 does, makes it fresh
+	cache.AddWithValidators("https://example.com/validated", val, stale)
+	if _, ok := cache.Get("https://example.com/validated"); !ok {
+		t.Errorf("expected the re-added entry to be fresh")
+	}
+}
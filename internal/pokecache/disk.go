@@ -0,0 +1,133 @@
+package pokecache
+
+import (
+  "crypto/sha256"
+  "encoding/hex"
+  "encoding/json"
+  "errors"
+  "os"
+  "path/filepath"
+  "strings"
+  "time"
+)
+
+// diskStore keeps one file per key in dir so entries survive restarts
+type diskStore struct {
+  dir string
+  ttl time.Duration
+}
+
+type diskEntry struct {
+  Key string `json:"key"`
+  CreatedAt time.Time `json:"created_at"`
+  Val []byte `json:"val"`
+  ETag string `json:"etag,omitempty"`
+  LastModified string `json:"last_modified,omitempty"`
+}
+
+func (e diskEntry) validators() Validators {
+  return Validators{ETag: e.ETag, LastModified: e.LastModified}
+}
+
+// path hashes the key because urls aren't safe file names
+func (d *diskStore) path(key string) string {
+  sum := sha256.Sum256([]byte(key))
+  return filepath.Join(d.dir, hex.EncodeToString(sum[:]) + ".json")
+}
+
+// get reads key's entry whatever its age. Expired ones are kept too, offline they're better than nothing
+func (d *diskStore) get(key string) (diskEntry, bool) {
+  data, err := os.ReadFile(d.path(key))
+  if err != nil {
+    return diskEntry{}, false
+  }
+
+  var entry diskEntry
+  if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+    return diskEntry{}, false
+  }
+
+  return entry, true
+}
+
+func (d *diskStore) expired(entry diskEntry) bool {
+  return d.ttl > 0 && time.Since(entry.CreatedAt) > d.ttl
+}
+
+// put writes through a temp file so a half written entry is never read back
+func (d *diskStore) put(key string, val []byte, validators Validators, createdAt time.Time) error {
+  data, err := json.Marshal(diskEntry{
+    Key: key,
+    CreatedAt: createdAt,
+    Val: val,
+    ETag: validators.ETag,
+    LastModified: validators.LastModified,
+  })
+  if err != nil {
+    return err
+  }
+
+  if err := os.MkdirAll(d.dir, 0o755); err != nil {
+    return err
+  }
+
+  tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+  if err != nil {
+    return err
+  }
+
+  if _, err := tmp.Write(data); err != nil {
+    tmp.Close()
+    os.Remove(tmp.Name())
+    return err
+  }
+
+  if err := tmp.Close(); err != nil {
+    os.Remove(tmp.Name())
+    return err
+  }
+
+  return os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *diskStore) delete(key string) {
+  os.Remove(d.path(key))
+}
+
+// isEntryFile is whether name is one of ours: an entry named by path, or a temp file a put didn't finish
+func isEntryFile(name string) bool {
+  if strings.HasPrefix(name, "entry-") && strings.HasSuffix(name, ".tmp") {
+    return true
+  }
+
+  hash, found := strings.CutSuffix(name, ".json")
+  if !found || len(hash) != sha256.Size * 2 {
+    return false
+  }
+  _, err := hex.DecodeString(hash)
+  return err == nil && hash == strings.ToLower(hash)
+}
+
+// clear removes every entry file, anything else that happens to live in dir is left alone
+// since --cache-dir can point anywhere
+func (d *diskStore) clear() error {
+  files, err := os.ReadDir(d.dir)
+  if errors.Is(err, os.ErrNotExist) {
+    return nil
+  }
+  if err != nil {
+    return err
+  }
+
+  for _, file := range files {
+    if file.IsDir() || !isEntryFile(file.Name()) {
+      continue
+    }
+
+    if err := os.Remove(filepath.Join(d.dir, file.Name())); err != nil {
+      return err
+    }
+  }
+
+  return nil
+}
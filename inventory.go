@@ -0,0 +1,151 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "sort"
+  "strconv"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// a new player starts out like in the games, with some money and a few Poke Balls.
+// The mart never sells Master Balls (PokeAPI has them at cost 0), so the starting one is the only one
+const startingMoney = 3000
+
+func startingBag() map[string]int {
+  return map[string]int{"poke-ball": 5, "master-ball": 1}
+}
+
+// martStock is what the Poke Mart sells, by PokeAPI item name. Prices come from each item's cost
+var martStock = []string{
+  "poke-ball",
+  "great-ball",
+  "ultra-ball",
+  "potion",
+  "super-potion",
+  "hyper-potion",
+  "oran-berry",
+  "sitrus-berry",
+}
+
+// useItem takes one of item out of the bag, failing when there's none left
+func (cfg *config) useItem(item string) error {
+  if cfg.bag[item] <= 0 {
+    return fmt.Errorf("you don't have any %s left, buy some at the mart", item)
+  }
+
+  cfg.bag[item]--
+  if cfg.bag[item] == 0 {
+    delete(cfg.bag, item)
+  }
+
+  return nil
+}
+
+// martItem looks name up on PokeAPI, only items in martStock that have a price can be bought
+func (cfg *config) martItem(ctx context.Context, name string) (pokeapi.Item, error) {
+  stocked := false
+  for _, stock := range martStock {
+    stocked = stocked || stock == name
+  }
+  if !stocked {
+    return pokeapi.Item{}, didYouMean(fmt.Errorf("the mart doesn't sell %s", name), name, martStock)
+  }
+
+  item, err := cfg.pokeapiClient.GetItem(ctx, name)
+  if err != nil {
+    return pokeapi.Item{}, describeFetchError(err, "item", name)
+  }
+  if item.Cost <= 0 {
+    return pokeapi.Item{}, fmt.Errorf("%s isn't for sale", name)
+  }
+
+  return item, nil
+}
+
+func commandMart(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    result := martResult{Money: cfg.money, Items: []martItem{}}
+    for _, name := range martStock {
+      item, err := cfg.martItem(ctx, name)
+      if errors.Is(err, context.Canceled) || errors.Is(err, pokeapi.ErrOffline) {
+        return err
+      }
+      // one item PokeAPI can't price shouldn't close the whole mart
+      if err != nil {
+        continue
+      }
+      result.Items = append(result.Items, martItem{Name: item.Name, Cost: item.Cost})
+    }
+
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintln(w, "Welcome to the Poke Mart!")
+      for _, item := range result.Items {
+        fmt.Fprintf(w, " - %s: $%d\n", item.Name, item.Cost)
+      }
+      fmt.Fprintf(w, "You have $%d.\n", result.Money)
+    })
+  }
+}
+
+func commandBuy(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    name := args[0]
+    quantity := 1
+    if len(args) == 2 {
+      parsed, err := strconv.Atoi(args[1])
+      if err != nil || parsed < 1 {
+        return fmt.Errorf("quantity must be a whole number above 0, not %q", args[1])
+      }
+      quantity = parsed
+    }
+
+    item, err := cfg.martItem(ctx, name)
+    if err != nil {
+      return err
+    }
+
+    // checked before multiplying, a big enough quantity would overflow the cost
+    if quantity > cfg.money / item.Cost {
+      return fmt.Errorf("%s cost $%d each and you only have $%d, enough for %d", name, item.Cost, cfg.money, cfg.money / item.Cost)
+    }
+    cost := item.Cost * quantity
+
+    cfg.money -= cost
+    if cfg.bag == nil {
+      cfg.bag = make(map[string]int)
+    }
+    cfg.bag[name] += quantity
+
+    result := buyResult{Item: name, Quantity: quantity, Cost: cost, Money: cfg.money}
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Bought %d %s for $%d, you have $%d left.\n", result.Quantity, result.Item, result.Cost, result.Money)
+    })
+  }
+}
+
+func commandBag(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    result := bagResult{Money: cfg.money, Items: []bagItem{}}
+    names := keysOf(cfg.bag)
+    sort.Strings(names)
+    for _, name := range names {
+      result.Items = append(result.Items, bagItem{Name: name, Quantity: cfg.bag[name]})
+    }
+
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Money: $%d\n", result.Money)
+      if len(result.Items) == 0 {
+        fmt.Fprintln(w, "Your bag is empty.")
+        return
+      }
+      fmt.Fprintln(w, "Your bag:")
+      for _, item := range result.Items {
+        fmt.Fprintf(w, " - %s x%d\n", item.Name, item.Quantity)
+      }
+    })
+  }
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func martHandler(w http.ResponseWriter, r *http.Request, serverURL string) {
+  switch r.URL.Path {
+  case "/item/poke-ball":
+    fmt.Fprint(w, `{"name": "poke-ball", "cost": 200}`)
+  case "/item/great-ball":
+    fmt.Fprint(w, `{"name": "great-ball", "cost": 600}`)
+  case "/item/potion":
+    fmt.Fprint(w, `{"name": "potion", "cost": 0}`)
+  case "/pokemon/pikachu", "/pokemon-species/pikachu":
+    fmt.Fprint(w, `{"name": "pikachu", "capture_rate": 190}`)
+  default:
+    http.NotFound(w, r)
+  }
+}
+
+func TestMartListsPricedItems(t *testing.T) {
+  cfg, out := newTestConfig(t, martHandler)
+  cfg.output = outputJSON
+
+  if err := commandMart(cfg)(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  // potion has no price and the rest aren't on the fake PokeAPI, so they're left out
+  expected := `{"money":3000,"items":[{"name":"poke-ball","cost":200},{"name":"great-ball","cost":600}]}` + "\n"
+  if out.String() != expected {
+    t.Errorf("expected %q, got %q", expected, out.String())
+  }
+}
+
+func TestBuy(t *testing.T) {
+  cfg, _ := newTestConfig(t, martHandler)
+
+  if err := commandBuy(cfg)(context.Background(), []string{"great-ball", "3"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.money != 1200 || cfg.bag["great-ball"] != 3 {
+    t.Errorf("expected $1200 and 3 great balls, got $%d and %v", cfg.money, cfg.bag)
+  }
+
+  if err := commandBuy(cfg)(context.Background(), []string{"great-ball", "3"}); err == nil {
+    t.Errorf("expected not being able to afford it to fail")
+  }
+  if cfg.money != 1200 || cfg.bag["great-ball"] != 3 {
+    t.Errorf("expected a failed buy to change nothing, got $%d and %v", cfg.money, cfg.bag)
+  }
+
+  if err := commandBuy(cfg)(context.Background(), []string{"poke-ball", "92233720368547758"}); err == nil {
+    t.Errorf("expected a quantity whose cost overflows to fail")
+  }
+  if cfg.money != 1200 || cfg.bag["poke-ball"] != 5 {
+    t.Errorf("expected an overflowing buy to change nothing, got $%d and %v", cfg.money, cfg.bag)
+  }
+
+  err := commandBuy(cfg)(context.Background(), []string{"poke-bal"})
+  if err == nil || err.Error() != "the mart doesn't sell poke-bal, did you mean poke-ball?" {
+    t.Errorf("expected a suggestion, got %v", err)
+  }
+
+  if err := commandBuy(cfg)(context.Background(), []string{"poke-ball", "-2"}); err == nil {
+    t.Errorf("expected a negative quantity to fail")
+  }
+}
+
+func TestCatchUsesUpBalls(t *testing.T) {
+  cfg, _ := newTestConfig(t, martHandler)
+  cfg.sandbox = true
+  cfg.bag = map[string]int{"poke-ball": 2}
+
+  for i := 0; i < 2; i++ {
+    if err := commandCatch(cfg)(context.Background(), []string{"pikachu"}); err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+  }
+  if _, found := cfg.bag["poke-ball"]; found {
+    t.Errorf("expected both balls to be used up, got %v", cfg.bag)
+  }
+
+  err := commandCatch(cfg)(context.Background(), []string{"pikachu"})
+  if err == nil || !strings.Contains(err.Error(), "buy some at the mart") {
+    t.Errorf("expected an empty bag to stop the throw, got %v", err)
+  }
+}
+
+func TestStartingMasterBall(t *testing.T) {
+  cfg, _ := newTestConfig(t, martHandler)
+  cfg.sandbox = true
+
+  if err := commandCatch(cfg)(context.Background(), []string{"pikachu", "--ball", "master"}); err != nil {
+    t.Fatalf("expected the starting master ball to be thrown, got %v", err)
+  }
+  if len(cfg.ownedPokemon) != 1 {
+    t.Errorf("expected the master ball to catch, got %+v", cfg.ownedPokemon)
+  }
+
+  err := commandCatch(cfg)(context.Background(), []string{"pikachu", "--ball", "master"})
+  if err == nil || !strings.Contains(err.Error(), "Master Balls") {
+    t.Errorf("expected there to be only one master ball, got %v", err)
+  }
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// enterLocationArea makes area where the player is, so catch only finds what lives there
+func (cfg *config) enterLocationArea(area pokeapi.LocationArea) {
+  cfg.currentLocationArea = area.Name
+  cfg.currentEncounters = []string{}
+  cfg.currentEncounterLevels = make(map[string]levelRange)
+  for _, encounter := range area.PokemonEncounters {
+    cfg.currentEncounters = append(cfg.currentEncounters, encounter.Pokemon.Name)
+
+    // the widest range over every game version and encounter method
+    levels := levelRange{}
+    for _, version := range encounter.VersionDetails {
+      for _, detail := range version.EncounterDetails {
+        if levels.min == 0 || detail.MinLevel < levels.min {
+          levels.min = detail.MinLevel
+        }
+        levels.max = max(levels.max, detail.MaxLevel)
+      }
+    }
+    if levels.min > 0 && levels.max >= levels.min {
+      cfg.currentEncounterLevels[encounter.Pokemon.Name] = levels
+    }
+  }
+}
+
+// encounterLevels is the levels pokemonName is found at where the player is, sandboxLevels when that isn't known
+func (cfg *config) encounterLevels(pokemonName string) levelRange {
+  if levels, found := cfg.currentEncounterLevels[pokemonName]; found && !cfg.sandbox {
+    return levels
+  }
+
+  return sandboxLevels
+}
+
+// encounters are the pokemon living in the current location area. A location loaded from the
+// save file doesn't have them yet, so they're fetched the first time they're needed
+func (cfg *config) encounters(ctx context.Context) ([]string, error) {
+  if cfg.currentEncounters == nil {
+    area, err := cfg.pokeapiClient.GetLocationArea(ctx, cfg.currentLocationArea)
+    if err != nil {
+      return nil, describeFetchError(err, "location area", cfg.currentLocationArea)
+    }
+    cfg.enterLocationArea(area)
+  }
+
+  return cfg.currentEncounters, nil
+}
+
+// checkCanCatch stops catch from finding pokemon that don't live where the player is, unless --sandbox is on
+func (cfg *config) checkCanCatch(ctx context.Context, pokemonName string) error {
+  if cfg.sandbox {
+    return nil
+  }
+
+  if cfg.currentLocationArea == "" {
+    return errors.New("you need to be somewhere to catch pokemon, explore or travel to a location area first")
+  }
+
+  encounters, err := cfg.encounters(ctx)
+  if err != nil {
+    return err
+  }
+
+  for _, encounter := range encounters {
+    if encounter == pokemonName {
+      return nil
+    }
+  }
+
+  return didYouMean(fmt.Errorf("there's no %s in %s", pokemonName, cfg.currentLocationArea), pokemonName, encounters)
+}
+
+func commandTravel(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    locationName := args[0]
+
+    area, err := cfg.pokeapiClient.GetLocationArea(ctx, locationName)
+    if err != nil {
+      err = describeFetchError(err, "location area", locationName)
+      if errors.Is(err, pokeapi.ErrNotFound) {
+        err = didYouMean(err, locationName, cfg.locationAreaNames(ctx))
+      }
+      return err
+    }
+
+    cfg.rememberLocationArea(locationName)
+    cfg.enterLocationArea(area)
+
+    result := travelResult{Location: area.Name}
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "You travelled to %s.\n", result.Location)
+      fmt.Fprintln(w, "Use explore to see which pokemon live here.")
+    })
+  }
+}
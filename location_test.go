@@ -0,0 +1,82 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+func locationHandler(w http.ResponseWriter, r *http.Request, serverURL string) {
+  switch r.URL.Path {
+  case "/location-area/canalave-city-area":
+    fmt.Fprint(w, `{"name": "canalave-city-area", "pokemon_encounters": [{"pokemon": {"name": "tentacool"}}, {"pokemon": {"name": "wingull"}}]}`)
+  case "/pokemon/tentacool", "/pokemon-species/tentacool":
+    fmt.Fprint(w, `{"name": "tentacool", "capture_rate": 190}`)
+  default:
+    http.NotFound(w, r)
+  }
+}
+
+func TestCatchNeedsALocation(t *testing.T) {
+  cfg, _ := newTestConfig(t, locationHandler)
+
+  err := commandCatch(cfg)(context.Background(), []string{"tentacool"})
+  if err == nil || !strings.Contains(err.Error(), "explore or travel") {
+    t.Errorf("expected to be told to go somewhere first, got %v", err)
+  }
+}
+
+func TestCatchOnlyWhereThePokemonLives(t *testing.T) {
+  cfg, _ := newTestConfig(t, locationHandler)
+
+  if err := commandTravel(cfg)(context.Background(), []string{"canalave-city-area"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.currentLocationArea != "canalave-city-area" {
+    t.Errorf("expected to be in canalave-city-area, got %q", cfg.currentLocationArea)
+  }
+
+  if err := commandCatch(cfg)(context.Background(), []string{"tentacool"}); err != nil {
+    t.Errorf("expected tentacool to be catchable here, got %v", err)
+  }
+
+  err := commandCatch(cfg)(context.Background(), []string{"wingul"})
+  if err == nil || err.Error() != "there's no wingul in canalave-city-area, did you mean wingull?" {
+    t.Errorf("expected wingul to not be found here, got %v", err)
+  }
+
+  cfg.sandbox = true
+  err = commandCatch(cfg)(context.Background(), []string{"mewtwo"})
+  if err == nil || !strings.HasPrefix(err.Error(), "no Pokemon named 'mewtwo'") {
+    t.Errorf("expected sandbox mode to go straight to PokeAPI, got %v", err)
+  }
+}
+
+func TestCurrentLocationIsSaved(t *testing.T) {
+  cfg, _ := newTestConfig(t, locationHandler)
+  cfg.saveFilePath = filepath.Join(t.TempDir(), "pokedex.json")
+
+  if err := commandExplore(cfg)(context.Background(), []string{"canalave-city-area"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if err := saveCaughtPokemon(cfg); err != nil {
+    t.Fatalf("unexpected error saving: %v", err)
+  }
+
+  loaded, _ := newTestConfig(t, locationHandler)
+  loaded.saveFilePath = cfg.saveFilePath
+  if err := loadCaughtPokemon(loaded); err != nil {
+    t.Fatalf("unexpected error loading: %v", err)
+  }
+  if loaded.currentLocationArea != "canalave-city-area" {
+    t.Errorf("expected the location to be loaded, got %q", loaded.currentLocationArea)
+  }
+
+  // the encounters aren't saved, catch fetches them again
+  if err := loaded.checkCanCatch(context.Background(), "wingull"); err != nil {
+    t.Errorf("expected wingull to be catchable after loading, got %v", err)
+  }
+}
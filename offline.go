@@ -0,0 +1,31 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "io"
+)
+
+func commandOffline(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if len(args) > 0 {
+      switch args[0] {
+      case "on":
+        cfg.pokeapiClient.SetOffline(true)
+      case "off":
+        cfg.pokeapiClient.SetOffline(false)
+      default:
+        return errUsage
+      }
+    }
+
+    result := offlineResult{Offline: cfg.pokeapiClient.Offline()}
+    return cfg.emit(result, func(w io.Writer) {
+      if result.Offline {
+        fmt.Fprintln(w, "Offline mode is on, only cached and fixture data will be used")
+      } else {
+        fmt.Fprintln(w, "Offline mode is off")
+      }
+    })
+  }
+}
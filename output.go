@@ -0,0 +1,177 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "io"
+  "time"
+)
+
+const (
+  outputText = "text"
+  outputJSON = "json"
+)
+
+// emit writes result as a single line of json in json mode, otherwise text prints it for people.
+// The json field names are what other tools depend on, so only ever add to the result types below
+func (cfg *config) emit(result any, text func(w io.Writer)) error {
+  if cfg.output != outputJSON {
+    text(cfg.out)
+    return nil
+  }
+
+  if err := json.NewEncoder(cfg.out).Encode(result); err != nil {
+    return fmt.Errorf("error encoding json output %w", err)
+  }
+
+  return nil
+}
+
+type helpResult struct {
+  Commands []helpCommand `json:"commands"`
+}
+
+type helpCommand struct {
+  Name string `json:"name"`
+  Description string `json:"description"`
+  Usage string `json:"usage"`
+  Aliases []string `json:"aliases"`
+  Examples []string `json:"examples"`
+}
+
+type mapResult struct {
+  Locations []string `json:"locations"`
+  Next *string `json:"next"`
+  Previous *string `json:"previous"`
+}
+
+type exploreResult struct {
+  Location string `json:"location"`
+  Pokemon []string `json:"pokemon"`
+}
+
+// travelResult is the location area travel took the player to
+type travelResult struct {
+  Location string `json:"location"`
+}
+
+// catchResult has the chance as a percentage, the outcome as "caught" or "escaped" and the ball as
+// one of the names --ball takes
+type catchResult struct {
+  Name string `json:"name"`
+  Chance float64 `json:"chance"`
+  Outcome string `json:"outcome"`
+  Ball string `json:"ball"`
+  BallsLeft int `json:"balls_left"`
+  // ID, Level and Shiny describe the pokemon when it was caught
+  ID int `json:"id,omitempty"`
+  Level int `json:"level,omitempty"`
+  Shiny bool `json:"shiny,omitempty"`
+}
+
+type inspectResult struct {
+  Name string `json:"name"`
+  Height int `json:"height"`
+  Weight int `json:"weight"`
+  Stats []inspectStat `json:"stats"`
+  Types []string `json:"types"`
+  ID int `json:"id"`
+  Nickname string `json:"nickname,omitempty"`
+  Level int `json:"level"`
+  Nature string `json:"nature,omitempty"`
+  Gender string `json:"gender,omitempty"`
+  Shiny bool `json:"shiny"`
+  CaughtAt time.Time `json:"caught_at"`
+  CaughtIn string `json:"caught_in,omitempty"`
+}
+
+// inspectStat's Value is the stat at the pokemon's level, BaseStat is the species' base
+type inspectStat struct {
+  Name string `json:"name"`
+  BaseStat int `json:"base_stat"`
+  Value int `json:"value"`
+  IV int `json:"iv"`
+  EV int `json:"ev"`
+}
+
+type pokedexResult struct {
+  Pokemon []string `json:"pokemon"`
+  Owned []pokedexEntry `json:"owned"`
+}
+
+type pokedexEntry struct {
+  ID int `json:"id"`
+  Species string `json:"species"`
+  Nickname string `json:"nickname,omitempty"`
+  Level int `json:"level"`
+  Shiny bool `json:"shiny"`
+}
+
+type nicknameResult struct {
+  ID int `json:"id"`
+  Species string `json:"species"`
+  Nickname string `json:"nickname"`
+}
+
+type saveResult struct {
+  Path string `json:"path"`
+  Pokemon int `json:"pokemon"`
+}
+
+type cacheStatsResult struct {
+  Entries int `json:"entries"`
+  Bytes int `json:"bytes"`
+  Hits int `json:"hits"`
+  Misses int `json:"misses"`
+  Evictions int `json:"evictions"`
+  Expirations int `json:"expirations"`
+  OldestAgeSeconds float64 `json:"oldest_age_seconds"`
+}
+
+type cacheListResult struct {
+  Keys []string `json:"keys"`
+}
+
+type cacheEvictResult struct {
+  Key string `json:"key"`
+  Evicted bool `json:"evicted"`
+}
+
+type cacheClearResult struct {
+  Cleared bool `json:"cleared"`
+}
+
+type seedResult struct {
+  Seed int64 `json:"seed"`
+}
+
+type martResult struct {
+  Money int `json:"money"`
+  Items []martItem `json:"items"`
+}
+
+type martItem struct {
+  Name string `json:"name"`
+  Cost int `json:"cost"`
+}
+
+type buyResult struct {
+  Item string `json:"item"`
+  Quantity int `json:"quantity"`
+  Cost int `json:"cost"`
+  Money int `json:"money"`
+}
+
+type bagResult struct {
+  Money int `json:"money"`
+  Items []bagItem `json:"items"`
+}
+
+type bagItem struct {
+  Name string `json:"name"`
+  Quantity int `json:"quantity"`
+}
+
+type offlineResult struct {
+  Offline bool `json:"offline"`
+}
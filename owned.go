@@ -0,0 +1,257 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "io"
+  "math/rand"
+  "slices"
+  "sort"
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// ownedPokemon is one pokemon the player caught. Only the species is kept from PokeAPI,
+// everything else about it is rolled at catch time so two pikachu are never quite the same
+type ownedPokemon struct {
+  ID int `json:"id"`
+  // Species is the PokeAPI pokemon name, e.g. "pikachu"
+  Species string `json:"species"`
+  Nickname string `json:"nickname,omitempty"`
+  Level int `json:"level"`
+  // IVs and EVs are keyed by PokeAPI stat name, e.g. "special-attack"
+  IVs map[string]int `json:"ivs"`
+  EVs map[string]int `json:"evs"`
+  Nature string `json:"nature"`
+  // Gender is "male", "female" or "genderless"
+  Gender string `json:"gender"`
+  Shiny bool `json:"shiny"`
+  CaughtAt time.Time `json:"caught_at"`
+  // CaughtIn is the location area it was caught in, empty when caught in sandbox mode
+  CaughtIn string `json:"caught_in,omitempty"`
+}
+
+// name is what the player calls it, the nickname if it has one
+func (p ownedPokemon) name() string {
+  if p.Nickname != "" {
+    return p.Nickname
+  }
+
+  return p.Species
+}
+
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// natures raise one stat by 10% and lower another by 10%, the five where both are the same stat do nothing
+var natures = map[string][2]string{
+  "hardy": {"attack", "attack"}, "lonely": {"attack", "defense"}, "brave": {"attack", "speed"},
+  "adamant": {"attack", "special-attack"}, "naughty": {"attack", "special-defense"},
+  "bold": {"defense", "attack"}, "docile": {"defense", "defense"}, "relaxed": {"defense", "speed"},
+  "impish": {"defense", "special-attack"}, "lax": {"defense", "special-defense"},
+  "timid": {"speed", "attack"}, "hasty": {"speed", "defense"}, "serious": {"speed", "speed"},
+  "jolly": {"speed", "special-attack"}, "naive": {"speed", "special-defense"},
+  "modest": {"special-attack", "attack"}, "mild": {"special-attack", "defense"}, "quiet": {"special-attack", "speed"},
+  "bashful": {"special-attack", "special-attack"}, "rash": {"special-attack", "special-defense"},
+  "calm": {"special-defense", "attack"}, "gentle": {"special-defense", "defense"}, "sassy": {"special-defense", "speed"},
+  "careful": {"special-defense", "special-attack"}, "quirky": {"special-defense", "special-defense"},
+}
+
+// shinyOdds is the one in shinyOdds chance of a wild pokemon being shiny, as in Gen VI onwards
+const shinyOdds = 4096
+
+// levelRange is the levels a pokemon is found at in a location area
+type levelRange struct {
+  min int
+  max int
+}
+
+// sandboxLevels is used when there's no location area to say how strong its pokemon are
+var sandboxLevels = levelRange{min: 5, max: 30}
+
+// rollOwnedPokemon makes the pokemon a catch turns into. Every random part comes from rng,
+// so the same seed catches the same pokemon
+func rollOwnedPokemon(rng *rand.Rand, species pokeapi.PokemonSpecies, pokemonName string, levels levelRange, place string, caughtAt time.Time) ownedPokemon {
+  owned := ownedPokemon{
+    Species: pokemonName,
+    Level: levels.min + rng.Intn(levels.max - levels.min + 1),
+    IVs: make(map[string]int),
+    EVs: make(map[string]int),
+    Gender: rollGender(rng, species.GenderRate),
+    CaughtAt: caughtAt,
+    CaughtIn: place,
+  }
+
+  // wild pokemon haven't battled yet, so their EVs all start at 0
+  for _, stat := range statNames {
+    owned.IVs[stat] = rng.Intn(32)
+    owned.EVs[stat] = 0
+  }
+
+  natureNames := keysOf(natures)
+  sort.Strings(natureNames)
+  owned.Nature = natureNames[rng.Intn(len(natureNames))]
+
+  owned.Shiny = rng.Intn(shinyOdds) == 0
+
+  return owned
+}
+
+// rollGender follows PokeAPI's gender_rate, the chance of being female in eighths, -1 for genderless species
+func rollGender(rng *rand.Rand, genderRate int) string {
+  if genderRate < 0 {
+    return "genderless"
+  }
+  if rng.Intn(8) < genderRate {
+    return "female"
+  }
+
+  return "male"
+}
+
+// statValue is the stat at the pokemon's level with the Gen III+ formula:
+// hp = (2*base + iv + ev/4) * level/100 + level + 10, the others (2*base + iv + ev/4) * level/100 + 5 times the nature
+func statValue(stat string, base int, owned ownedPokemon) int {
+  scaled := (2 * base + owned.IVs[stat] + owned.EVs[stat] / 4) * owned.Level / 100
+  if stat == "hp" {
+    return scaled + owned.Level + 10
+  }
+
+  value := float64(scaled + 5)
+  if nature, found := natures[owned.Nature]; found && nature[0] != nature[1] {
+    if nature[0] == stat {
+      value *= 1.1
+    }
+    if nature[1] == stat {
+      value *= 0.9
+    }
+  }
+
+  return int(value)
+}
+
+// addOwnedPokemon gives owned the next free ID and puts it with the rest
+func (cfg *config) addOwnedPokemon(owned ownedPokemon) ownedPokemon {
+  owned.ID = 1
+  for _, other := range cfg.ownedPokemon {
+    owned.ID = max(owned.ID, other.ID + 1)
+  }
+  cfg.ownedPokemon = append(cfg.ownedPokemon, owned)
+
+  return owned
+}
+
+// findOwnedPokemon looks a pokemon up by ID, then nickname, then species as long as only one of it is owned
+func (cfg *config) findOwnedPokemon(query string) (*ownedPokemon, error) {
+  if id, err := strconv.Atoi(query); err == nil {
+    for i := range cfg.ownedPokemon {
+      if cfg.ownedPokemon[i].ID == id {
+        return &cfg.ownedPokemon[i], nil
+      }
+    }
+    return nil, fmt.Errorf("you don't have a pokemon #%d", id)
+  }
+
+  for i := range cfg.ownedPokemon {
+    if cfg.ownedPokemon[i].Nickname == query {
+      return &cfg.ownedPokemon[i], nil
+    }
+  }
+
+  matches := []int{}
+  for i := range cfg.ownedPokemon {
+    if cfg.ownedPokemon[i].Species == query {
+      matches = append(matches, i)
+    }
+  }
+
+  switch len(matches) {
+  case 0:
+    return nil, didYouMean(fmt.Errorf("you have not caught that pokemon"), query, cfg.ownedNames())
+  case 1:
+    return &cfg.ownedPokemon[matches[0]], nil
+  }
+
+  ids := []string{}
+  for _, i := range matches {
+    ids = append(ids, strconv.Itoa(cfg.ownedPokemon[i].ID))
+  }
+  return nil, fmt.Errorf("you have %d %s, pick one by its ID: %s", len(matches), query, strings.Join(ids, ", "))
+}
+
+// ownedNames is every nickname and species the player can refer to their pokemon by
+func (cfg *config) ownedNames() []string {
+  seen := make(map[string]bool)
+  for _, owned := range cfg.ownedPokemon {
+    seen[owned.Species] = true
+    if owned.Nickname != "" {
+      seen[owned.Nickname] = true
+    }
+  }
+
+  return keysOf(seen)
+}
+
+// migrateCaughtPokemon turns the one-per-species pokemon of version 1 and 2 save files into owned pokemon.
+// Nothing was rolled for them back then, so they get a level 5 and the rest left blank
+func migrateCaughtPokemon(caught map[string]pokeapi.Pokemon) []ownedPokemon {
+  names := keysOf(caught)
+  sort.Strings(names)
+
+  owned := []ownedPokemon{}
+  for i, name := range names {
+    owned = append(owned, ownedPokemon{
+      ID: i + 1,
+      Species: name,
+      Level: sandboxLevels.min,
+      IVs: make(map[string]int),
+      EVs: make(map[string]int),
+    })
+  }
+
+  return owned
+}
+
+func commandNickname(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    owned, err := cfg.findOwnedPokemon(args[0])
+    if err != nil {
+      return err
+    }
+
+    nickname := ""
+    if len(args) == 2 {
+      nickname = args[1]
+    }
+
+    if nickname != "" {
+      if _, err := strconv.Atoi(nickname); err == nil {
+        return fmt.Errorf("a nickname can't be a number, those are for IDs")
+      }
+      // findOwnedPokemon tries nicknames before species, a nickname like that would hide every pokemon of that species.
+      // The PokeAPI index is best effort, offline only the owned species get checked
+      if slices.ContainsFunc(cfg.ownedPokemon, func(other ownedPokemon) bool { return other.Species == nickname }) ||
+        slices.Contains(cfg.pokemonNames(ctx), nickname) {
+        return fmt.Errorf("a nickname can't be a pokemon's name, those are for species")
+      }
+      for _, other := range cfg.ownedPokemon {
+        if other.Nickname == nickname && other.ID != owned.ID {
+          return fmt.Errorf("#%d is already called %s", other.ID, nickname)
+        }
+      }
+    }
+
+    owned.Nickname = nickname
+
+    result := nicknameResult{ID: owned.ID, Species: owned.Species, Nickname: owned.Nickname}
+    return cfg.emit(result, func(w io.Writer) {
+      if result.Nickname == "" {
+        fmt.Fprintf(w, "#%d is just called %s again.\n", result.ID, result.Species)
+        return
+      }
+      fmt.Fprintf(w, "#%d %s is now called %s.\n", result.ID, result.Species, result.Nickname)
+    })
+  }
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "math/rand"
+  "net/http"
+  "reflect"
+  "strings"
+  "testing"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+func TestRollOwnedPokemon(t *testing.T) {
+  caughtAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+  species := pokeapi.PokemonSpecies{Name: "pikachu", GenderRate: 4}
+  levels := levelRange{min: 3, max: 7}
+
+  first := rollOwnedPokemon(rand.New(rand.NewSource(9)), species, "pikachu", levels, "viridian-forest-area", caughtAt)
+  second := rollOwnedPokemon(rand.New(rand.NewSource(9)), species, "pikachu", levels, "viridian-forest-area", caughtAt)
+  if !reflect.DeepEqual(first, second) {
+    t.Errorf("expected the same seed to roll the same pokemon, got %+v and %+v", first, second)
+  }
+
+  rng := rand.New(rand.NewSource(1))
+  for i := 0; i < 100; i++ {
+    owned := rollOwnedPokemon(rng, species, "pikachu", levels, "viridian-forest-area", caughtAt)
+    if owned.Level < levels.min || owned.Level > levels.max {
+      t.Errorf("level %d is outside %+v", owned.Level, levels)
+    }
+    for _, stat := range statNames {
+      if owned.IVs[stat] < 0 || owned.IVs[stat] > 31 || owned.EVs[stat] != 0 {
+        t.Errorf("unexpected %s IV %d and EV %d", stat, owned.IVs[stat], owned.EVs[stat])
+      }
+    }
+    if _, found := natures[owned.Nature]; !found {
+      t.Errorf("unexpected nature %q", owned.Nature)
+    }
+    if owned.Species != "pikachu" || owned.CaughtIn != "viridian-forest-area" || !owned.CaughtAt.Equal(caughtAt) {
+      t.Errorf("unexpected pokemon %+v", owned)
+    }
+  }
+}
+
+func TestRollGender(t *testing.T) {
+  rng := rand.New(rand.NewSource(1))
+  for i := 0; i < 20; i++ {
+    if gender := rollGender(rng, -1); gender != "genderless" {
+      t.Errorf("expected genderless, got %s", gender)
+    }
+    if gender := rollGender(rng, 0); gender != "male" {
+      t.Errorf("expected male, got %s", gender)
+    }
+    if gender := rollGender(rng, 8); gender != "female" {
+      t.Errorf("expected female, got %s", gender)
+    }
+  }
+}
+
+func TestStatValue(t *testing.T) {
+  // Bulbapedia's worked example, a level 78 adamant Garchomp
+  garchomp := ownedPokemon{
+    Level: 78,
+    Nature: "adamant",
+    IVs: map[string]int{"hp": 24, "attack": 12, "special-attack": 16},
+    EVs: map[string]int{"hp": 74, "attack": 190, "special-attack": 48},
+  }
+
+  cases := []struct {
+    stat string
+    base int
+    expected int
+  }{
+    {stat: "hp", base: 108, expected: 289},
+    {stat: "attack", base: 130, expected: 278},
+    // adamant lowers special attack, (2*80 + 16 + 48/4) * 78/100 = 146, (146 + 5) * 0.9 = 135
+    {stat: "special-attack", base: 80, expected: 135},
+  }
+
+  for _, c := range cases {
+    if actual := statValue(c.stat, c.base, garchomp); actual != c.expected {
+      t.Errorf("%s: expected %d, got %d", c.stat, c.expected, actual)
+    }
+  }
+}
+
+func TestCatchingTwiceKeepsBoth(t *testing.T) {
+  cfg, out := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    fmt.Fprint(w, `{"name": "pikachu", "capture_rate": 190, "gender_rate": 4, "stats": [{"base_stat": 35, "stat": {"name": "hp"}}]}`)
+  })
+  cfg.sandbox = true
+  cfg.bag["master-ball"] = 2
+
+  for i := 0; i < 2; i++ {
+    if err := commandCatch(cfg)(context.Background(), []string{"pikachu", "--ball", "master"}); err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+  }
+  if len(cfg.ownedPokemon) != 2 || cfg.ownedPokemon[0].ID != 1 || cfg.ownedPokemon[1].ID != 2 {
+    t.Fatalf("expected two pikachu with their own IDs, got %+v", cfg.ownedPokemon)
+  }
+
+  err := commandInspect(cfg)(context.Background(), []string{"pikachu"})
+  if err == nil || !strings.Contains(err.Error(), "pick one by its ID: 1, 2") {
+    t.Errorf("expected inspecting by species to be ambiguous, got %v", err)
+  }
+
+  if err := commandNickname(cfg)(context.Background(), []string{"2", "sparky"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if err := commandNickname(cfg)(context.Background(), []string{"1", "sparky"}); err == nil {
+    t.Errorf("expected a nickname that's taken to fail")
+  }
+  if err := commandNickname(cfg)(context.Background(), []string{"1", "42"}); err == nil {
+    t.Errorf("expected a number to not be a nickname")
+  }
+  cfg.ownedPokemon = append(cfg.ownedPokemon, ownedPokemon{ID: 3, Species: "bulbasaur"})
+  if err := commandNickname(cfg)(context.Background(), []string{"1", "bulbasaur"}); err == nil {
+    t.Errorf("expected a species name to not be a nickname")
+  }
+
+  cfg.output = outputJSON
+  for _, query := range []string{"2", "sparky"} {
+    out.Reset()
+    if err := commandInspect(cfg)(context.Background(), []string{query}); err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+
+    var result inspectResult
+    if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+      t.Fatalf("unexpected output %q: %v", out.String(), err)
+    }
+    if result.ID != 2 || result.Nickname != "sparky" || result.Name != "pikachu" || result.Level == 0 {
+      t.Errorf("inspect %s: unexpected result %+v", query, result)
+    }
+  }
+
+  if err := commandInspect(cfg)(context.Background(), []string{"9"}); err == nil {
+    t.Errorf("expected an ID nobody has to fail")
+  }
+}
@@ -1,47 +1,80 @@
 package main
 
 import (
+  "context"
   "fmt"
   "strings"
-  "bufio" 
+  "errors"
+  "flag"
+  "io"
   "os"
-  "encoding/json" 
-  "net/http"
+  "os/signal"
+  "path/filepath"
+  "slices"
+  "sort"
   "time"
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/lineedit"
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
   "github.com/Pradhyumna789/Pokedex_Cli/internal/pokecache"
-  "bytes"
-  "io"
   "math/rand"
 )
 
-var cache *pokecache.Cache
-
-var mapOfCaughtPokemon = make(map[string]Pokemon)
-
-type cliCommand struct {
-  name string
-  description string
-  callback func([]string) error
+// config holds the state shared between commands for the whole session
+type config struct {
+  pokeapiClient *pokeapi.Client
+  cache *pokecache.Cache
+  nextLocationsURL *string
+  previousLocationsURL *string
+  // ownedPokemon is every pokemon caught, in the order they were caught, see owned.go
+  ownedPokemon []ownedPokemon
+  saveFilePath string
+  // autosaveBlocked is set while the save file couldn't be loaded, so it isn't overwritten with
+  // an empty collection. Only a load that works or an explicit save clears it
+  autosaveBlocked bool
+
+  // out is where commands print, in the format named by output (outputText or outputJSON)
+  out io.Writer
+  output string
+
+  // names seen so far, offered by tab completion
+  seenLocationAreas map[string]bool
+  seenPokemon map[string]bool
+
+  // every name PokeAPI has, for "did you mean" suggestions, see suggest.go
+  pokemonNameIndex []string
+  locationAreaNameIndex []string
+
+  // currentLocationArea is where the player is, set by explore and travel, see location.go.
+  // sandbox lets catch find any pokemon from anywhere instead
+  currentLocationArea string
+  currentEncounters []string
+  currentEncounterLevels map[string]levelRange
+  sandbox bool
+
+  // rng is where every random roll comes from, so a run can be replayed with the same seed
+  rng *rand.Rand
+  seed int64
+
+  // money and bag (item name to how many) are the player's inventory, see inventory.go
+  money int
+  bag map[string]int
 }
 
-func commandExit(args []string) error {
-  fmt.Println("Closing the Pokedex... Goodbye!")
-  os.Exit(0)
-  return nil
-}
+// errExit is returned by the exit command so the main loop can save and shut down cleanly
+var errExit = errors.New("exit")
 
-func commandHelp(commands map[string]cliCommand) func([]string) error {
-  return func(args []string) error {
-    fmt.Println("Welcome to the Pokedex!") 
-    fmt.Println("Usage:")
-    fmt.Println("")
+func commandExit(ctx context.Context, args []string) error {
+  return errExit
+}
 
-    for _, v := range commands {
-      fmt.Printf("%s: %s\n", v.name, v.description) 
-    }
+// defaultCacheDir is pokedexcli inside the user's cache dir (~/.cache/pokedexcli on linux)
+func defaultCacheDir() string {
+  cacheDir, err := os.UserCacheDir()
+  if err != nil {
+    return ""
+  }
 
-      return nil 
-    }
+  return filepath.Join(cacheDir, "pokedexcli")
 }
 
 func cleanInput(text string) []string { 
@@ -50,724 +83,551 @@ func cleanInput(text string) []string {
 }
 
 
-type pokeApiResponse struct {
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
-}
+// showLocationAreas moves the map/mapb position to page and prints it
+func showLocationAreas(cfg *config, page pokeapi.LocationAreaList) error {
+  cfg.nextLocationsURL = page.Next
+  cfg.previousLocationsURL = page.Previous
 
-type exploreCommandJson struct {
-	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
-}
+  result := mapResult{
+    Locations: []string{},
+    Next: page.Next,
+    Previous: page.Previous,
+  }
+  for _, location := range page.Results {
+    cfg.rememberLocationArea(location.Name)
+    result.Locations = append(result.Locations, location.Name)
+  }
 
-type Pokemon struct {
-	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height    int `json:"height"`
-	HeldItems []struct {
-		Item struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"item"`
-		VersionDetails []struct {
-			Rarity  int `json:"rarity"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			Order        int `json:"order"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []any  `json:"past_abilities"`
-	PastTypes     []struct {
-		Generation struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"generation"`
-		Types []struct {
-			Slot int `json:"slot"`
-			Type struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"type"`
-		} `json:"types"`
-	} `json:"past_types"`
-	Species struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       any    `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  any    `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      any    `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale any    `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string `json:"front_default"`
-				FrontFemale  any    `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string `json:"back_default"`
-				BackFemale       any    `json:"back_female"`
-				BackShiny        string `json:"back_shiny"`
-				BackShinyFemale  any    `json:"back_shiny_female"`
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       any    `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  any    `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      any    `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale any    `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+  return cfg.emit(result, func(w io.Writer) {
+    for _, location := range result.Locations {
+      fmt.Fprintln(w, location)
+    }
+  })
 }
 
-func fetchLocations(args []string) error {
-
-    url := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/")
-
-    if cachedData, found := cache.Get(url); found {
-      var pokeapiRes pokeApiResponse
-      decoder := json.NewDecoder(bytes.NewReader(cachedData))
-      err_decode := decoder.Decode(&pokeapiRes)
-
-      if err_decode != nil {
-        return fmt.Errorf("cachedData not successfully decoded %w", err_decode)
-      }
-
-      for _, location := range pokeapiRes.Results {
-        fmt.Println(location.Name) 
-      }
-
-      return nil
-
+func fetchLocations(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if cfg.nextLocationsURL == nil {
+      return fmt.Errorf("you're on the last page")
     }
 
-    client := &http.Client{
-      Timeout: time.Second * 20,
+    pokeApiRes, err := cfg.pokeapiClient.ListLocationAreas(ctx, *cfg.nextLocationsURL)
+    if err != nil {
+      return describeFetchError(err, "page", *cfg.nextLocationsURL)
     }
 
-    req, err := http.NewRequest("GET", url, nil) 
-    
-    if err != nil {
-      return fmt.Errorf("error creating a GET request %w", err)
+    return showLocationAreas(cfg, pokeApiRes)
+  }
+}
+
+func fetchLocationsBackwards(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if cfg.previousLocationsURL == nil {
+      return fmt.Errorf("you're on the first page")
     }
 
-    res, err := client.Do(req)
+    pokeApiRes, err := cfg.pokeapiClient.ListLocationAreas(ctx, *cfg.previousLocationsURL)
     if err != nil {
-      return fmt.Errorf("error getting a response %w", err)
+      return describeFetchError(err, "page", *cfg.previousLocationsURL)
     }
 
-    defer res.Body.Close()
+    return showLocationAreas(cfg, pokeApiRes)
+  }
+}
 
-    body, err := io.ReadAll(res.Body)
+func commandExplore(cfg *config) func(context.Context, []string) error  {
+  return func(ctx context.Context, args []string) error {
+    locationName := args[0]
+
+    exploreJson, err := cfg.pokeapiClient.GetLocationArea(ctx, locationName)
     if err != nil {
-      return fmt.Errorf("Error in converting response's body to a slice of bytes %w", err)
+      err = describeFetchError(err, "location area", locationName)
+      if errors.Is(err, pokeapi.ErrNotFound) {
+        err = didYouMean(err, locationName, cfg.locationAreaNames(ctx))
+      }
+      return err
     }
 
-    cache.Add(url, body)
-
-    var pokeApiRes pokeApiResponse
-    decoder := json.NewDecoder(bytes.NewReader(body)) 
-    err_decode := decoder.Decode(&pokeApiRes)
+    cfg.rememberLocationArea(locationName)
+    cfg.enterLocationArea(exploreJson)
 
-    if err_decode != nil {
-      return fmt.Errorf("error decoding json %w", err_decode)
+    result := exploreResult{
+      Location: exploreJson.Name,
+      Pokemon: []string{},
     }
-   
-    for _, location := range pokeApiRes.Results {
-      fmt.Println(location.Name)
+    for _, pokemonEncounter := range exploreJson.PokemonEncounters {
+      cfg.rememberPokemon(pokemonEncounter.Pokemon.Name)
+      result.Pokemon = append(result.Pokemon, pokemonEncounter.Pokemon.Name)
     }
 
-    return nil
-}
-
-func fetchLocationsBackwards(args []string) error {
-    url := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/")
-
-    if cachedData, found := cache.Get(url); found {
-      var pokeapiRes pokeApiResponse
-      decoder := json.NewDecoder(bytes.NewReader(cachedData))
-      err_decode := decoder.Decode(&pokeapiRes)
-
-      if err_decode != nil {
-        return fmt.Errorf("cachedData not successfully decoded %w", err_decode)
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintln(w, "Found Pokemon:")
+      for _, pokemon := range result.Pokemon {
+        fmt.Fprintf(w, " - %s\n", pokemon) 
       }
+    })
+  }
+} 
 
-      for _, location := range pokeapiRes.Results {
-        fmt.Println(location.Name) 
-      }
-
-      return nil
-
+func commandCatch(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    pokemonName, ball, err := parseCatchArgs(args)
+    if err != nil {
+      return err
     }
 
-    client := &http.Client{
-      Timeout: time.Second * 20,
+    if err := cfg.checkCanCatch(ctx, pokemonName); err != nil {
+      return err
     }
 
-    req, err := http.NewRequest("GET", url, nil) 
-    
-    if err != nil {
-      return fmt.Errorf("error creating a GET request %w", err)
+    // checked up front so nothing is fetched for a throw that can't happen
+    if cfg.bag[ball.item] <= 0 {
+      if !slices.Contains(martStock, ball.item) {
+        return fmt.Errorf("you don't have any %ss left, and the mart doesn't sell them", ball.displayName)
+      }
+      return fmt.Errorf("you don't have any %ss, buy some at the mart", ball.displayName)
     }
 
-    res, err := client.Do(req)
+    pokemonNameJson, err := cfg.pokeapiClient.GetPokemon(ctx, pokemonName)
     if err != nil {
-      return fmt.Errorf("error getting a response %w", err)
+      err = describeFetchError(err, "Pokemon", pokemonName)
+      if errors.Is(err, pokeapi.ErrNotFound) {
+        err = didYouMean(err, pokemonName, cfg.pokemonNames(ctx))
+      }
+      return err
     }
 
-    defer res.Body.Close()
-
-    body, err := io.ReadAll(res.Body)
+    speciesURL := pokemonNameJson.Species.URL
+    if speciesURL == "" {
+      speciesURL = cfg.pokeapiClient.PokemonSpeciesURL(pokemonName)
+    }
+    species, err := cfg.pokeapiClient.GetPokemonSpecies(ctx, speciesURL)
     if err != nil {
-      return fmt.Errorf("Error in converting response's body to a slice of bytes %w", err)
+      return describeFetchError(err, "Pokemon species", pokemonName)
     }
 
-    cache.Add(url, body)
-
-    var pokeApiRes pokeApiResponse
-    decoder := json.NewDecoder(bytes.NewReader(body)) 
-    err_decode := decoder.Decode(&pokeApiRes)
+    // difficulty of catching a pokemon is decided on its species' capture rate and the ball thrown,
+    // wild pokemon haven't been battled so they're always at full HP
+    maxHP := baseStat(pokemonNameJson, "hp")
+    chance := captureChance(species.CaptureRate, ball, maxHP, maxHP)
 
-    if err_decode != nil {
-      return fmt.Errorf("error decoding json %w", err_decode)
-    }
-   
-    for i := len(pokeApiRes.Results) - 1; i >= 0; i-- {
-      fmt.Println(pokeApiRes.Results[i].Name)
+    if err := cfg.useItem(ball.item); err != nil {
+      return err
     }
 
-    return nil
-}
-
-func commandExplore() func([]string) error  {
-  return func(args []string) error {
-     if len(args) == 0 {
-        return fmt.Errorf("Location are name is required")
-     }
-
-     locationName := args[0]
-     url := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%s", locationName)
-
-     if cachedData, found := cache.Get(url); found {
-      var exploreJson exploreCommandJson
-      decoder := json.NewDecoder(bytes.NewReader(cachedData))
-      err_decode := decoder.Decode(&exploreJson)
-
-      if err_decode != nil {
-        return fmt.Errorf("cachedData not successfully decoded %w", err_decode)
-      }
+    result := catchResult{
+      Name: pokemonName,
+      Chance: chance,
+      Outcome: "escaped",
+      Ball: ball.name,
+      BallsLeft: cfg.bag[ball.item],
+    }
 
-      fmt.Println("Found Pokemon:")
-      for _, pokemonEncounter := range exploreJson.PokemonEncounters {
-        fmt.Printf(" - %s\n", pokemonEncounter.Pokemon.Name) 
+    if ball.alwaysCatches || cfg.rng.Float64() * 100 < chance {
+      place := cfg.currentLocationArea
+      if cfg.sandbox {
+        place = ""
       }
+      owned := cfg.addOwnedPokemon(rollOwnedPokemon(cfg.rng, species, pokemonName, cfg.encounterLevels(pokemonName), place, time.Now()))
 
-      return nil
-
+      result.Outcome = "caught"
+      result.ID = owned.ID
+      result.Level = owned.Level
+      result.Shiny = owned.Shiny
     }
 
-    client := &http.Client{
-      Timeout: time.Second * 20,
-    }
-
-    req, err := http.NewRequest("GET", url, nil) 
-    
+    err = cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Throwing a %s at %s...\n", ball.displayName, pokemonName)
+      if result.Outcome == "caught" {
+        fmt.Fprintf(w, "%s was caught!\n", pokemonName)
+        if result.Shiny {
+          fmt.Fprintln(w, "It's shiny!")
+        }
+        fmt.Fprintf(w, "It's a level %d %s, inspect it with inspect %d.\n", result.Level, pokemonName, result.ID)
+      } else {
+        fmt.Fprintf(w, "%s escaped!\n", pokemonName)
+      }
+      fmt.Fprintf(w, "%d %ss left.\n", result.BallsLeft, ball.displayName)
+    })
     if err != nil {
-      return fmt.Errorf("error creating a GET request %w", err)
+      return err
     }
 
-    res, err := client.Do(req)
-    if err != nil {
-      return fmt.Errorf("error getting a response %w", err)
+    if result.Outcome == "caught" {
+      if err := autosave(cfg); err != nil {
+        return fmt.Errorf("%s was caught but could not be saved: %w", pokemonName, err)
+      }
     }
 
-    defer res.Body.Close()
+    return nil
+  }
+}
 
-    body, err := io.ReadAll(res.Body)
+func commandInspect(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    owned, err := cfg.findOwnedPokemon(args[0])
     if err != nil {
-      return fmt.Errorf("Error in converting response's body to a slice of bytes %w", err)
+      return err
     }
 
-    cache.Add(url, body)
+    // only the species is saved, the rest comes from PokeAPI (or the cache)
+    caughtPokemon, err := cfg.pokeapiClient.GetPokemon(ctx, owned.Species)
+    if err != nil {
+      return describeFetchError(err, "Pokemon", owned.Species)
+    }
+
+    result := inspectResult{
+      Name: caughtPokemon.Name,
+      Height: caughtPokemon.Height,
+      Weight: caughtPokemon.Weight,
+      Stats: []inspectStat{},
+      Types: []string{},
+      ID: owned.ID,
+      Nickname: owned.Nickname,
+      Level: owned.Level,
+      Nature: owned.Nature,
+      Gender: owned.Gender,
+      Shiny: owned.Shiny,
+      CaughtAt: owned.CaughtAt,
+      CaughtIn: owned.CaughtIn,
+    }
+    for _, stat := range caughtPokemon.Stats {
+      result.Stats = append(result.Stats, inspectStat{
+        Name: stat.Stat.Name,
+        BaseStat: stat.BaseStat,
+        Value: statValue(stat.Stat.Name, stat.BaseStat, *owned),
+        IV: owned.IVs[stat.Stat.Name],
+        EV: owned.EVs[stat.Stat.Name],
+      })
+    }
+    for _, typeInfo := range caughtPokemon.Types { 
+      result.Types = append(result.Types, typeInfo.Type.Name)
+    }
+
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "#%d %s\n", result.ID, owned.name())
+      fmt.Fprintf(w, "Name: %s\n", result.Name)
+      fmt.Fprintf(w, "Level: %d\n", result.Level)
+      fmt.Fprintf(w, "Height: %d\n", result.Height)
+      fmt.Fprintf(w, "Weight: %d\n", result.Weight)
+      if result.Gender != "" {
+        fmt.Fprintf(w, "Gender: %s\n", result.Gender)
+      }
+      if result.Nature != "" {
+        fmt.Fprintf(w, "Nature: %s\n", result.Nature)
+      }
+      if result.Shiny {
+        fmt.Fprintln(w, "Shiny: yes")
+      }
+      if result.CaughtIn != "" {
+        fmt.Fprintf(w, "Caught in %s on %s\n", result.CaughtIn, result.CaughtAt.Format("2006-01-02"))
+      } else if !result.CaughtAt.IsZero() {
+        fmt.Fprintf(w, "Caught on %s\n", result.CaughtAt.Format("2006-01-02"))
+      }
+      fmt.Fprintln(w, "Stats: ")
 
-    var exploreJson exploreCommandJson
-    decoder := json.NewDecoder(bytes.NewReader(body)) 
-    err_decode := decoder.Decode(&exploreJson)
+      for _, stat := range result.Stats {
+        fmt.Fprintf(w, " -%s: %d (base %d, IV %d, EV %d)\n", stat.Name, stat.Value, stat.BaseStat, stat.IV, stat.EV)
+      } 
 
-    if err_decode != nil {
-      return fmt.Errorf("error decoding json %w", err_decode)
-    }
-   
-    fmt.Println("Found Pokemon:")
-    for _, pokemonEncounter := range exploreJson.PokemonEncounters {
-      fmt.Printf(" - %s\n", pokemonEncounter.Pokemon.Name) 
-    }
+      fmt.Fprintln(w, "Types:")
 
-    return nil
+      for _, typeName := range result.Types { 
+        fmt.Fprintf(w, " - %s\n", typeName)
+      }
+    })
   }
-} 
-
-func commandCatch() func([]string) error {
-  return func (args []string) error {
-    if len(args) == 0 {
-      return fmt.Errorf("Requires pokemon name to catch")
-    } 
-
-    pokemonName := args[0]
-    url := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", pokemonName)
+}
 
-    client := &http.Client{
-      Timeout: time.Second * 20,
-    }
+func commandPokedex(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    // Pokemon is every species caught once, Owned every pokemon caught
+    result := pokedexResult{Pokemon: []string{}, Owned: []pokedexEntry{}}
+    species := make(map[string]bool)
+    for _, owned := range cfg.ownedPokemon {
+      species[owned.Species] = true
+      result.Owned = append(result.Owned, pokedexEntry{
+        ID: owned.ID,
+        Species: owned.Species,
+        Nickname: owned.Nickname,
+        Level: owned.Level,
+        Shiny: owned.Shiny,
+      })
+    }
+    result.Pokemon = keysOf(species)
+    sort.Strings(result.Pokemon)
+
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintln(w, "Your Pokedex:") 
+      for _, owned := range result.Owned {
+        line := fmt.Sprintf(" - #%d %s", owned.ID, owned.Species)
+        if owned.Nickname != "" {
+          line += fmt.Sprintf(" (%s)", owned.Nickname)
+        }
+        line += fmt.Sprintf(", level %d", owned.Level)
+        if owned.Shiny {
+          line += ", shiny"
+        }
+        fmt.Fprintln(w, line)
+      }
+    })
+  }
+}
 
-    req, err := http.NewRequest("GET", url, nil) 
-    if err != nil {
-      return fmt.Errorf("error creating a GET request %w", err)
-    }
+func main() {
+  os.Exit(run())
+}
 
-    res, err := client.Do(req)
-    if err != nil {
-      return fmt.Errorf("error getting a response %w", err)
-    }
+// run is main without the os.Exit, so everything deferred still happens before the exit code is returned
+func run() int {
+
+  saveFilePath := flag.String("save-file", defaultSaveFilePath(), "where caught pokemon are saved between sessions")
+  cacheDir := flag.String("cache-dir", defaultCacheDir(), "where PokeAPI responses are kept between sessions, empty to only cache in memory")
+  diskTTL := flag.Duration("disk-ttl", 7 * 24 * time.Hour, "how long PokeAPI responses stay valid on disk, 0 to keep them forever")
+  cacheMaxEntries := flag.Int("cache-max-entries", 0, "most PokeAPI responses kept in memory, 0 for no limit")
+  cacheMaxBytes := flag.Int("cache-max-bytes", 32 << 20, "most bytes of PokeAPI responses kept in memory, 0 for no limit")
+  offline := flag.Bool("offline", false, "never touch the network, only use cached responses and --fixtures")
+  historyFile := flag.String("history-file", defaultHistoryFilePath(), "where the prompt's history is kept, empty to not keep it")
+  maxAttempts := flag.Int("max-attempts", 3, "how many times a PokeAPI request is tried before giving up")
+  fixtureDir := flag.String("fixtures", "", "directory of saved PokeAPI json mirroring the url paths, e.g. api/v2/pokemon/pikachu/index.json")
+  scriptFile := flag.String("script", "", "run the commands in this file without prompting, - reads them from stdin")
+  failFast := flag.Bool("fail-fast", false, "stop a script at the first command that fails")
+  output := flag.String("output", outputText, "how commands print their results, text or json")
+  sandbox := flag.Bool("sandbox", false, "let catch find any pokemon from anywhere, not just the ones where you are")
+  seed := flag.Int64("seed", 0, "seed for every random roll, so a run can be replayed (default a new one every run)")
+  flag.Usage = func() {
+    fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [command [args...]]\n\n", os.Args[0])
+    fmt.Fprintln(flag.CommandLine.Output(), "With a command it runs just that one and exits, otherwise it starts the Pokedex prompt.")
+    fmt.Fprintln(flag.CommandLine.Output(), "")
+    fmt.Fprintln(flag.CommandLine.Output(), "Flags:")
+    flag.PrintDefaults()
+  }
+  // flags stop at the first word that isn't one, so everything after the command belongs to it
+  flag.Parse()
 
-    defer res.Body.Close()
+  if *output != outputText && *output != outputJSON {
+    fmt.Fprintf(os.Stderr, "--output must be %s or %s, not %q\n", outputText, outputJSON, *output)
+    return 2
+  }
 
-    body, err := io.ReadAll(res.Body)
-    if err != nil {
-      return fmt.Errorf("Error in converting response's body to a slice of bytes %w", err)
-    }
+  cacheOptions := []pokecache.Option{
+    pokecache.WithMaxEntries(*cacheMaxEntries),
+    pokecache.WithMaxBytes(*cacheMaxBytes),
+  }
+  if *cacheDir != "" {
+    cacheOptions = append(cacheOptions, pokecache.WithDiskStore(*cacheDir, *diskTTL))
+  }
 
-    var pokemonNameJson Pokemon 
-    decoder := json.NewDecoder(bytes.NewReader(body)) 
-    err_decode := decoder.Decode(&pokemonNameJson)
+  cache := pokecache.NewCache(10 * time.Second, cacheOptions...)
+  defer cache.Close()
+  pokeapiClient := pokeapi.NewClient(
+    cache,
+    pokeapi.WithOffline(*offline),
+    pokeapi.WithFixtureDir(*fixtureDir),
+    pokeapi.WithMaxAttempts(*maxAttempts),
+  )
+
+  firstLocationsURL := pokeapiClient.LocationAreasURL()
+  cfg := &config{
+    pokeapiClient: pokeapiClient,
+    cache: cache,
+    nextLocationsURL: &firstLocationsURL,
+    ownedPokemon: []ownedPokemon{},
+    saveFilePath: *saveFilePath,
+    money: startingMoney,
+    bag: startingBag(),
+    out: os.Stdout,
+    output: *output,
+    sandbox: *sandbox,
+  }
 
-    if err_decode != nil {
-      return fmt.Errorf("error decoding json %w", err_decode)
-    }
+  seedGiven := false
+  flag.Visit(func(f *flag.Flag) {
+    seedGiven = seedGiven || f.Name == "seed"
+  })
+  if !seedGiven {
+    *seed = newSeed()
+  }
+  cfg.reseed(*seed)
+  // stderr keeps it out of json output and piped results
+  fmt.Fprintf(os.Stderr, "Random seed %d, replay this run with --seed %d\n", cfg.seed, cfg.seed)
 
-    fmt.Printf("Throwing a Pokeball at %s...\n", pokemonName)
+  if err := loadCaughtPokemon(cfg); err != nil {
+    fmt.Fprintln(os.Stderr, "Could not load your saved pokemon: ", err)
+    fmt.Fprintf(os.Stderr, "%s won't be saved over until load or save works\n", cfg.saveFilePath)
+  }
 
-    // Seed uses the provided seed value to initialize the generator to a deterministic state.
-    // Seed should not be called concurrently with any other [Rand] method.
-    // difficulty of catching a pokemon is decided on the pokemon's base experience
-    
-    catchChance := 100 - (pokemonNameJson.BaseExperience / 10)  
+  commandsRegistry := getCommands(cfg)
 
-    if rand.Intn(100) < catchChance {
-      mapOfCaughtPokemon[pokemonName] = pokemonNameJson
-      fmt.Printf("%s was caught!\n", pokemonName)
-      fmt.Println("You may now inspect it with the inspect command.")
-    } else {
-      fmt.Printf("%s escaped!\n", pokemonName)
-    }
+  // outside the prompt Ctrl-C cancels whatever is running and stops there, the pokemon still get saved below
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+  defer stop()
 
-    return nil
-  }
-}
+  succeeded := true
+  switch {
+  case flag.NArg() > 0:
+    succeeded = runOneShot(ctx, commandsRegistry, flag.Args())
 
-func commandInspect() func([]string) error {
-  return func(args []string) error {
-    if len(args) == 0 {
-      return fmt.Errorf("Error - requires pokemon name to inspect it")
+  case *scriptFile != "" && *scriptFile != "-":
+    file, err := os.Open(*scriptFile)
+    if err != nil {
+      fmt.Fprintln(os.Stderr, "Could not open the script: ", err)
+      return 1
     }
+    defer file.Close()
 
-    pokemonName := args[0]
+    succeeded = runScript(ctx, commandsRegistry, file, *scriptFile, *failFast)
 
-    caughtPokemon, found := mapOfCaughtPokemon[pokemonName]
-    if !found {
-      return fmt.Errorf("you have not caught that pokemon")
-    } else {
-      fmt.Printf("Name: %s\n", caughtPokemon.Name)
-      fmt.Printf("Height: %d\n", caughtPokemon.Height)
-      fmt.Printf("Weight: %d\n", caughtPokemon.Weight)
-      fmt.Println("Stats: ")
+  // piped input is treated as a script too
+  case *scriptFile == "-" || !stdinIsTerminal():
+    succeeded = runScript(ctx, commandsRegistry, os.Stdin, "stdin", *failFast)
 
-      for _, stat := range caughtPokemon.Stats {
-        fmt.Printf(" -%s: %d\n", stat.Stat.Name, stat.BaseStat)
-      } 
-
-      fmt.Println("Types:")
+  default:
+    // the prompt handles Ctrl-C itself
+    stop()
+    runREPL(cfg, commandsRegistry, *historyFile)
+  }
 
-      for _, typeInfo := range caughtPokemon.Types { 
-        fmt.Printf(" - %s\n", typeInfo.Type.Name)
-      }
-    }
+  if err := autosave(cfg); err != nil {
+    fmt.Fprintln(os.Stderr, "Could not save your pokemon: ", err)
+    return 1
+  }
 
-    return nil
+  if !succeeded {
+    return 1
   }
+  return 0
+
 }
 
-func commandPokedex() func([]string) error {
-  return func(args []string) error {
-    fmt.Println("Your Pokedex:") 
-    for _, pokemon := range mapOfCaughtPokemon {
-      fmt.Printf(" - %s\n", pokemon.Name)
-    }
+// errUnknownCommand is returned by executeCommand when the first word isn't in the registry
+var errUnknownCommand = errors.New("unknown command")
 
-    return nil 
+// executeCommand runs the command named by the first word with the rest as its arguments
+func executeCommand(ctx context.Context, commands map[string]cliCommand, words []string) error {
+  commandEntered, found := lookupCommand(commands, words[0])
+  if !found {
+    return didYouMean(fmt.Errorf("%w %q", errUnknownCommand, words[0]), words[0], commandNames(commands))
   }
-}
 
-func main() {
+  args := words[1:]
+  if err := commandEntered.checkArgs(args); err != nil {
+    return err
+  }
 
-  cache = pokecache.NewCache(10 * time.Second)
+  err := commandEntered.callback(ctx, args)
+  if errors.Is(err, errUsage) {
+    return commandEntered.usageError()
+  }
 
-  commandsRegistry := make(map[string]cliCommand)
+  return err
+}
 
-  commandsRegistry["help"] = cliCommand{
-        name: "help",
-        description: "Displays a help message",
-        callback: commandHelp(commandsRegistry), // parentheses after commandHelp because it's returning a higher order funciton (closure)
+// runOneShot runs the single command given on the command line, e.g. `Pokedex_Cli inspect pikachu`
+func runOneShot(ctx context.Context, commands map[string]cliCommand, args []string) bool {
+  err := executeCommand(ctx, commands, cleanInput(strings.Join(args, " ")))
+  if err != nil && !errors.Is(err, errExit) {
+    fmt.Fprintln(os.Stderr, "Error executing command: ", err)
+    return false
   }
 
-  commandsRegistry["exit"] = cliCommand{
-        name: "exit",
-        description: "Exit the Pokedex",
-        callback: commandExit,
-  }
+  return true
+}
 
-  commandsRegistry["map"] = cliCommand {
-      name: "map",
-      description: "shows next 20 locations of the pokemon",
-      callback: fetchLocations,
+// runInterruptible runs fn with a context that an interrupt cancels
+func runInterruptible(interrupts chan os.Signal, fn func(ctx context.Context) error) error {
+  // an interrupt from before the command started isn't meant for it
+  for len(interrupts) > 0 {
+    <- interrupts
   }
 
-  commandsRegistry["mapb"] = cliCommand {
-      name: "mapb",
-      description: "shows previous 20 locations of the pokemon",
-      callback: fetchLocationsBackwards,
-  }
+  ctx, cancel := context.WithCancel(context.Background())
+  defer cancel()
 
-  commandsRegistry["explore"] = cliCommand {
-      name: "explore",
-      description: "explore pokemons in a particular location by it's name",
-      callback: commandExplore(), // parentheses after commandExplore because this is also returning a higher order function like commandHelp (closure)
-  }
+  // the watcher has to be gone before we return, otherwise it could still take the next command's interrupt
+  done := make(chan struct{})
+  exited := make(chan struct{})
+  go func() {
+    defer close(exited)
+    select {
+    case <- interrupts:
+      cancel()
+    case <- done:
+    }
+  }()
 
-  commandsRegistry["catch"] = cliCommand {
-      name: "catch",
-      description: "catch some pokemon",
-      callback: commandCatch(),
-  }
+  err := fn(ctx)
+  close(done)
+  <- exited
 
-  commandsRegistry["inspect"] = cliCommand {
-      name: "inspect",
-      description: "inspect details of the caught pokemon",
-      callback: commandInspect(),
-  }
+  return err
+}
+
+func runREPL(cfg *config, commandsRegistry map[string]cliCommand, historyFile string) {
 
-  commandsRegistry["pokedex"] = cliCommand {
-      name: "pokedex",
-      description: "prints a list of all the pokemon the user has caught",
-      callback: commandPokedex(),
+  editor := lineedit.NewEditor(os.Stdin, os.Stdout)
+  editor.SetCompleter(completer(cfg, commandsRegistry))
+  if historyFile != "" {
+    if err := editor.SetHistoryFile(historyFile); err != nil {
+      fmt.Println("Could not load your command history: ", err)
+    }
   }
 
-  scanner := bufio.NewScanner(os.Stdin)
+  // SIGINT never kills the prompt, while a command runs it cancels just that command.
+  // At the prompt itself the terminal is raw, so Ctrl-C shows up as ErrInterrupted instead
+  interrupts := make(chan os.Signal, 1)
+  signal.Notify(interrupts, os.Interrupt)
+  defer signal.Stop(interrupts)
 
   fmt.Println("Welcome to the Pokedex!")
+  interruptedAtEmptyPrompt := false
   for {
-      fmt.Print("Pokedex > ")
-
-      scanner.Scan()
-      scannedText := scanner.Text() 
+      scannedText, err := editor.ReadLine("Pokedex > ")
+      if errors.Is(err, lineedit.ErrInterrupted) {
+        if strings.TrimSpace(scannedText) != "" {
+          interruptedAtEmptyPrompt = false
+          continue
+        }
+        if interruptedAtEmptyPrompt {
+          break
+        }
+        interruptedAtEmptyPrompt = true
+        fmt.Println("(press Ctrl-C again to exit)")
+        continue
+      }
+      interruptedAtEmptyPrompt = false
+      // end of input (Ctrl-D) shuts down the same way the exit command does
+      if err != nil {
+        fmt.Println("")
+        break
+      }
+      editor.AddHistory(scannedText)
 
       displaySlice := cleanInput(scannedText) 
-      displayWord := displaySlice[0]
-
-      commandEntered, found := commandsRegistry[displayWord]
+      if len(displaySlice) == 0 {
+        continue
+      }
 
-      if !found {
+      err = runInterruptible(interrupts, func(ctx context.Context) error {
+        return executeCommand(ctx, commandsRegistry, displaySlice)
+      })
+      if errors.Is(err, errExit) {
+        break
+      }
+      if errors.Is(err, context.Canceled) {
+        fmt.Println("Cancelled")
+      } else if errors.Is(err, errUnknownCommand) {
         fmt.Println("Unknown command")
-      } else {
-        args := []string{}
-        if len(displaySlice) > 1 {
-          args = displaySlice[1:]
+        var suggestion *suggestionError
+        if errors.As(err, &suggestion) {
+          fmt.Printf("Did you mean %s?\n", suggestion.alternatives())
         }
-
-        err := commandEntered.callback(args)
-        if err != nil {
-          fmt.Println("Error executing command: ", err)
-        }
-      }       
+      } else if err != nil {
+        fmt.Println("Error executing command: ", err)
+      }
 
   }  
 
-}
-
-
-
+  fmt.Println("Closing the Pokedex... Goodbye!")
 
+}
@@ -1,7 +1,21 @@
 package main
 
 import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "flag"
+  "fmt"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
   "testing"
+  "time"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokecache"
 )
 
 func TestCleanInput(t *testing.T) {
@@ -43,3 +57,308 @@ func TestCleanInput(t *testing.T) {
 }
 
 
+// newTestConfig gives commands a session backed by an httptest PokeAPI, with their output in the returned buffer
+func newTestConfig(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, serverURL string)) (*config, *bytes.Buffer) {
+  var server *httptest.Server
+  server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    handler(w, r, server.URL)
+  }))
+  t.Cleanup(server.Close)
+
+  cache := pokecache.NewCache(time.Minute)
+  t.Cleanup(cache.Close)
+
+  pokeapiClient := pokeapi.NewClient(cache, pokeapi.WithBaseURL(server.URL))
+  firstLocationsURL := pokeapiClient.LocationAreasURL()
+  out := &bytes.Buffer{}
+  cfg := &config{
+    pokeapiClient: pokeapiClient,
+    cache: cache,
+    nextLocationsURL: &firstLocationsURL,
+    ownedPokemon: []ownedPokemon{},
+    saveFilePath: filepath.Join(t.TempDir(), "pokedex.json"),
+    out: out,
+    output: outputText,
+    money: startingMoney,
+    bag: startingBag(),
+  }
+  cfg.reseed(1)
+
+  return cfg, out
+}
+
+func TestMapPagination(t *testing.T) {
+  cfg, _ := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    if r.URL.Query().Get("offset") == "20" {
+      fmt.Fprintf(w, `{"next": null, "previous": "%s/location-area/", "results": [{"name": "second-page-area"}]}`, serverURL)
+      return
+    }
+    fmt.Fprintf(w, `{"next": "%s/location-area/?offset=20", "previous": null, "results": [{"name": "first-page-area"}]}`, serverURL)
+  })
+
+  mapCommand := fetchLocations(cfg)
+  mapbCommand := fetchLocationsBackwards(cfg)
+
+  if err := mapbCommand(context.Background(), nil); err == nil {
+    t.Errorf("expected mapb to fail before the first page was shown")
+  }
+
+  if err := mapCommand(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.nextLocationsURL == nil || cfg.previousLocationsURL != nil {
+    t.Errorf("expected only a next page after the first map")
+  }
+
+  if err := mapCommand(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.nextLocationsURL != nil || cfg.previousLocationsURL == nil {
+    t.Errorf("expected only a previous page on the last page")
+  }
+
+  if err := mapCommand(context.Background(), nil); err == nil {
+    t.Errorf("expected map to fail on the last page")
+  }
+
+  if err := mapbCommand(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.nextLocationsURL == nil || cfg.previousLocationsURL != nil {
+    t.Errorf("expected mapb to go back to the first page")
+  }
+}
+
+func TestCatchUnknownPokemon(t *testing.T) {
+  cfg, _ := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    http.NotFound(w, r)
+  })
+
+  cfg.sandbox = true
+  err := commandCatch(cfg)(context.Background(), []string{"pikachuu"})
+  if err == nil || err.Error() != "no Pokemon named 'pikachuu'" {
+    t.Errorf("expected a friendly not found error, got %v", err)
+  }
+  if !errors.Is(err, pokeapi.ErrNotFound) {
+    t.Errorf("expected the error to wrap pokeapi.ErrNotFound")
+  }
+}
+
+func TestCatchMissingSpecies(t *testing.T) {
+  cfg, _ := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    if r.URL.Path == "/pokemon/pikachu" {
+      fmt.Fprintf(w, `{"name": "pikachu", "species": {"url": "%s/pokemon-species/25"}}`, serverURL)
+      return
+    }
+    http.NotFound(w, r)
+  })
+
+  cfg.sandbox = true
+  err := commandCatch(cfg)(context.Background(), []string{"pikachu"})
+  if err == nil || err.Error() != "no Pokemon species named 'pikachu'" {
+    t.Errorf("expected a friendly not found error, got %v", err)
+  }
+  if !errors.Is(err, pokeapi.ErrNotFound) {
+    t.Errorf("expected the error to wrap pokeapi.ErrNotFound")
+  }
+  if cfg.bag["poke-ball"] != startingBag()["poke-ball"] {
+    t.Errorf("expected no ball to be thrown, have %d left", cfg.bag["poke-ball"])
+  }
+}
+
+func TestJSONOutput(t *testing.T) {
+  cfg, out := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    fmt.Fprint(w, `{"name": "canalave-city-area", "pokemon_encounters": [{"pokemon": {"name": "tentacool"}}, {"pokemon": {"name": "wingull"}}]}`)
+  })
+  cfg.output = outputJSON
+
+  if err := commandExplore(cfg)(context.Background(), []string{"canalave-city-area"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if err := commandPokedex(cfg)(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  expected := `{"location":"canalave-city-area","pokemon":["tentacool","wingull"]}
+{"pokemon":[],"owned":[]}
+`
+  if out.String() != expected {
+    t.Errorf("expected %q, got %q", expected, out.String())
+  }
+}
+
+func TestExecuteCommandChecksArgs(t *testing.T) {
+  calls := 0
+  commands := map[string]cliCommand{
+    "inspect": {
+      name: "inspect",
+      usage: "inspect <pokemon>",
+      minArgs: 1,
+      maxArgs: 1,
+      aliases: []string{"i"},
+      callback: func(ctx context.Context, args []string) error {
+        calls++
+        return nil
+      },
+    },
+    "cache": {
+      name: "cache",
+      usage: "cache stats|list|evict <url>|clear",
+      minArgs: 1,
+      maxArgs: 2,
+      callback: commandCache(&config{}),
+    },
+  }
+
+  cases := []struct {
+    words []string
+    expectedError string
+  }{
+    {
+      words: []string{"inspect"},
+      expectedError: "usage: inspect <pokemon>",
+    },
+    {
+      words: []string{"inspect", "pikachu", "mew"},
+      expectedError: "usage: inspect <pokemon>",
+    },
+    {
+      words: []string{"inspect", "pikachu"},
+    },
+    {
+      words: []string{"i", "pikachu"},
+    },
+    {
+      words: []string{"cache", "bogus"},
+      expectedError: "usage: cache stats|list|evict <url>|clear",
+    },
+    {
+      words: []string{"cache", "evict"},
+      expectedError: "usage: cache stats|list|evict <url>|clear",
+    },
+  }
+
+  for _, c := range cases {
+    err := executeCommand(context.Background(), commands, c.words)
+    if c.expectedError == "" && err != nil {
+      t.Errorf("%v: unexpected error %v", c.words, err)
+    }
+    if c.expectedError != "" && (err == nil || err.Error() != c.expectedError) {
+      t.Errorf("%v: expected error %q, got %v", c.words, c.expectedError, err)
+    }
+  }
+
+  if calls != 2 {
+    t.Errorf("expected the callback to only run with valid args, ran %d times", calls)
+  }
+}
+
+func TestRunInterruptible(t *testing.T) {
+  interrupts := make(chan os.Signal, 1)
+
+  // a stale interrupt from before the command must not cancel it
+  interrupts <- os.Interrupt
+  err := runInterruptible(interrupts, func(ctx context.Context) error {
+    return ctx.Err()
+  })
+  if err != nil {
+    t.Errorf("expected a stale interrupt to be ignored, got %v", err)
+  }
+
+  err = runInterruptible(interrupts, func(ctx context.Context) error {
+    interrupts <- os.Interrupt
+    <- ctx.Done()
+    return ctx.Err()
+  })
+  if !errors.Is(err, context.Canceled) {
+    t.Errorf("expected the interrupt to cancel the command, got %v", err)
+  }
+}
+
+func TestRunOneShot(t *testing.T) {
+  var caughtWith []string
+  commands := map[string]cliCommand{
+    "catch": {
+      name: "catch",
+      usage: "catch <pokemon> [--ball <ball>]",
+      minArgs: 1,
+      maxArgs: -1,
+      callback: func(ctx context.Context, args []string) error {
+        caughtWith = args
+        return nil
+      },
+    },
+    "fail": {
+      name: "fail",
+      usage: "fail",
+      maxArgs: 0,
+      callback: func(ctx context.Context, args []string) error {
+        return errors.New("it broke")
+      },
+    },
+  }
+
+  cases := []struct {
+    args []string
+    expected bool
+  }{
+    {args: []string{"catch", "pikachu", "--ball", "master"}, expected: true},
+    {args: []string{"fail"}, expected: false},
+    {args: []string{"nonsense"}, expected: false},
+  }
+
+  for _, c := range cases {
+    if actual := runOneShot(context.Background(), commands, c.args); actual != c.expected {
+      t.Errorf("%v: expected %v, got %v", c.args, c.expected, actual)
+    }
+  }
+
+  if fmt.Sprint(caughtWith) != "[pikachu --ball master]" {
+    t.Errorf("expected catch to get its own flags, got %v", caughtWith)
+  }
+}
+
+func TestRunOneShotKeepsCommandFlags(t *testing.T) {
+  dir := t.TempDir()
+  fixtures := map[string]string{
+    "api/v2/pokemon/pikachu": `{"name": "pikachu", "species": {"url": "https://pokeapi.co/api/v2/pokemon-species/25/"}, "stats": [{"base_stat": 35, "stat": {"name": "hp"}}]}`,
+    "api/v2/pokemon-species/25": `{"name": "pikachu", "capture_rate": 190, "gender_rate": 4}`,
+  }
+  for urlPath, body := range fixtures {
+    fixtureDir := filepath.Join(dir, "fixtures", filepath.FromSlash(urlPath))
+    if err := os.MkdirAll(fixtureDir, 0o755); err != nil {
+      t.Fatal(err)
+    }
+    if err := os.WriteFile(filepath.Join(fixtureDir, "index.json"), []byte(body), 0o644); err != nil {
+      t.Fatal(err)
+    }
+  }
+  saveFilePath := filepath.Join(dir, "pokedex.json")
+
+  args, commandLine := os.Args, flag.CommandLine
+  t.Cleanup(func() {
+    os.Args, flag.CommandLine = args, commandLine
+  })
+  flag.CommandLine = flag.NewFlagSet("pokedex", flag.ContinueOnError)
+  os.Args = []string{
+    "pokedex", "--save-file", saveFilePath, "--cache-dir", "", "--offline", "--fixtures", filepath.Join(dir, "fixtures"),
+    "--history-file", "", "--seed", "1", "--sandbox", "catch", "pikachu", "--ball", "master",
+  }
+
+  if code := run(); code != 0 {
+    t.Fatalf("expected the one-shot catch to succeed, got exit code %d", code)
+  }
+
+  // only a Master Ball catches for sure, so the pokemon being saved means --ball reached catch
+  data, err := os.ReadFile(saveFilePath)
+  if err != nil {
+    t.Fatal(err)
+  }
+  var save saveFile
+  if err := json.Unmarshal(data, &save); err != nil {
+    t.Fatalf("unexpected save file %q: %v", data, err)
+  }
+  if len(save.OwnedPokemon) != 1 || save.OwnedPokemon[0].Species != "pikachu" || save.Bag["master-ball"] != 0 {
+    t.Errorf("expected pikachu caught with the master ball, got %+v", save)
+  }
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "path/filepath"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+// version 2 added the inventory, version 1 saves start out with the starting money and bag.
+// Version 3 replaced caught_pokemon, one PokeAPI blob per species, with owned_pokemon
+const saveFileVersion = 3
+
+// saveFile is what ends up on disk, the version lets us change the layout later on
+type saveFile struct {
+  Version int `json:"version"`
+  // CaughtPokemon is only read, from version 1 and 2 save files, see migrateCaughtPokemon
+  CaughtPokemon map[string]pokeapi.Pokemon `json:"caught_pokemon,omitempty"`
+  OwnedPokemon []ownedPokemon `json:"owned_pokemon"`
+  // CurrentLocationArea was added without a version bump, older save files just don't have it
+  CurrentLocationArea string `json:"current_location_area,omitempty"`
+  Money int `json:"money"`
+  Bag map[string]int `json:"bag"`
+}
+
+// defaultSaveFilePath is pokedex.json inside the user's config dir (~/.config/pokedexcli on linux)
+func defaultSaveFilePath() string {
+  configDir, err := os.UserConfigDir()
+  if err != nil {
+    return "pokedex.json"
+  }
+
+  return filepath.Join(configDir, "pokedexcli", "pokedex.json")
+}
+
+// defaultHistoryFilePath keeps the prompt's history next to the save file
+func defaultHistoryFilePath() string {
+  return filepath.Join(filepath.Dir(defaultSaveFilePath()), "history")
+}
+
+// saveCaughtPokemon writes the caught pokemon to cfg.saveFilePath,
+// going through a temp file so a crash halfway never leaves a broken save behind
+func saveCaughtPokemon(cfg *config) error {
+  data, err := json.Marshal(saveFile{
+    Version: saveFileVersion,
+    OwnedPokemon: cfg.ownedPokemon,
+    CurrentLocationArea: cfg.currentLocationArea,
+    Money: cfg.money,
+    Bag: cfg.bag,
+  })
+  if err != nil {
+    return fmt.Errorf("error encoding save file %w", err)
+  }
+
+  if err := os.MkdirAll(filepath.Dir(cfg.saveFilePath), 0o755); err != nil {
+    return fmt.Errorf("error creating save directory %w", err)
+  }
+
+  tmpPath := cfg.saveFilePath + ".tmp"
+  if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+    return fmt.Errorf("error writing save file %w", err)
+  }
+
+  if err := os.Rename(tmpPath, cfg.saveFilePath); err != nil {
+    return fmt.Errorf("error writing save file %w", err)
+  }
+
+  return nil
+}
+
+// autosave is the save after a catch and on exit, it's skipped while cfg.autosaveBlocked is set
+func autosave(cfg *config) error {
+  if cfg.autosaveBlocked {
+    return nil
+  }
+
+  return saveCaughtPokemon(cfg)
+}
+
+// loadCaughtPokemon replaces the caught pokemon with the ones in cfg.saveFilePath,
+// a missing save file just means nothing has been caught yet.
+// When it fails autosaves stop, so a save file we couldn't read is never replaced by an empty one
+func loadCaughtPokemon(cfg *config) error {
+  err := readSaveFile(cfg)
+  cfg.autosaveBlocked = err != nil
+  return err
+}
+
+func readSaveFile(cfg *config) error {
+  data, err := os.ReadFile(cfg.saveFilePath)
+  if errors.Is(err, os.ErrNotExist) {
+    cfg.ownedPokemon = []ownedPokemon{}
+    cfg.money = startingMoney
+    cfg.bag = startingBag()
+    return nil
+  }
+  if err != nil {
+    return fmt.Errorf("error reading save file %w", err)
+  }
+
+  var save saveFile
+  if err := json.Unmarshal(data, &save); err != nil {
+    return fmt.Errorf("error decoding save file %w", err)
+  }
+
+  switch save.Version {
+  case 1:
+    save.Money = startingMoney
+    save.Bag = startingBag()
+    save.OwnedPokemon = migrateCaughtPokemon(save.CaughtPokemon)
+  case 2:
+    save.OwnedPokemon = migrateCaughtPokemon(save.CaughtPokemon)
+  case saveFileVersion:
+  default:
+    return fmt.Errorf("unsupported save file version %d", save.Version)
+  }
+
+  if save.OwnedPokemon == nil {
+    save.OwnedPokemon = []ownedPokemon{}
+  }
+
+  if save.Bag == nil {
+    save.Bag = make(map[string]int)
+  }
+
+  cfg.ownedPokemon = save.OwnedPokemon
+  cfg.money = save.Money
+  cfg.bag = save.Bag
+  // the encounters get fetched again when catch first needs them
+  cfg.currentLocationArea = save.CurrentLocationArea
+  cfg.currentEncounters = nil
+  return nil
+}
+
+func commandSave(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if err := saveCaughtPokemon(cfg); err != nil {
+      return err
+    }
+    // saving on purpose means the old file isn't wanted any more
+    cfg.autosaveBlocked = false
+
+    result := saveResult{Path: cfg.saveFilePath, Pokemon: len(cfg.ownedPokemon)}
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Saved %d pokemon to %s\n", result.Pokemon, result.Path)
+    })
+  }
+}
+
+func commandLoad(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if err := loadCaughtPokemon(cfg); err != nil {
+      return err
+    }
+
+    result := saveResult{Path: cfg.saveFilePath, Pokemon: len(cfg.ownedPokemon)}
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Loaded %d pokemon from %s\n", result.Pokemon, result.Path)
+    })
+  }
+}
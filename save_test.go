@@ -0,0 +1,156 @@
+package main
+
+import (
+  "context"
+  "flag"
+  "io"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+func TestSaveLoadOwnedPokemon(t *testing.T) {
+  saveFilePath := filepath.Join(t.TempDir(), "nested", "pokedex.json")
+
+  cfg := &config{
+    ownedPokemon: []ownedPokemon{
+      {ID: 1, Species: "pikachu", Level: 12, Nature: "jolly", IVs: map[string]int{"speed": 31}},
+      {ID: 2, Species: "pikachu", Nickname: "sparky", Level: 7},
+    },
+    saveFilePath: saveFilePath,
+  }
+
+  if err := saveCaughtPokemon(cfg); err != nil {
+    t.Fatalf("unexpected error saving: %v", err)
+  }
+
+  loaded := &config{saveFilePath: saveFilePath}
+  if err := loadCaughtPokemon(loaded); err != nil {
+    t.Fatalf("unexpected error loading: %v", err)
+  }
+
+  if len(loaded.ownedPokemon) != 2 {
+    t.Fatalf("expected both pikachu to survive a save and load, got %+v", loaded.ownedPokemon)
+  }
+  first := loaded.ownedPokemon[0]
+  if first.Level != 12 || first.Nature != "jolly" || first.IVs["speed"] != 31 {
+    t.Errorf("unexpected first pikachu %+v", first)
+  }
+  if loaded.ownedPokemon[1].Nickname != "sparky" {
+    t.Errorf("expected the nickname to be kept, got %+v", loaded.ownedPokemon[1])
+  }
+}
+
+func TestLoadMissingSaveFile(t *testing.T) {
+  cfg := &config{saveFilePath: filepath.Join(t.TempDir(), "pokedex.json")}
+
+  if err := loadCaughtPokemon(cfg); err != nil {
+    t.Fatalf("expected a missing save file to not be an error, got %v", err)
+  }
+
+  if cfg.ownedPokemon == nil || len(cfg.ownedPokemon) != 0 {
+    t.Errorf("expected an empty pokedex")
+  }
+}
+
+func TestLoadVersion1SaveFile(t *testing.T) {
+  saveFilePath := filepath.Join(t.TempDir(), "pokedex.json")
+  v1 := `{"version": 1, "caught_pokemon": {"pikachu": {"name": "pikachu"}, "bulbasaur": {"name": "bulbasaur"}}}`
+  if err := os.WriteFile(saveFilePath, []byte(v1), 0o644); err != nil {
+    t.Fatal(err)
+  }
+
+  cfg := &config{saveFilePath: saveFilePath}
+  if err := loadCaughtPokemon(cfg); err != nil {
+    t.Fatalf("unexpected error loading: %v", err)
+  }
+
+  expected := []ownedPokemon{
+    {ID: 1, Species: "bulbasaur", Level: 5},
+    {ID: 2, Species: "pikachu", Level: 5},
+  }
+  if len(cfg.ownedPokemon) != len(expected) {
+    t.Fatalf("expected %d migrated pokemon, got %+v", len(expected), cfg.ownedPokemon)
+  }
+  for i := range expected {
+    if actual := cfg.ownedPokemon[i]; actual.ID != expected[i].ID || actual.Species != expected[i].Species || actual.Level != expected[i].Level {
+      t.Errorf("expected %+v, got %+v", expected[i], actual)
+    }
+  }
+  if cfg.money != startingMoney || cfg.bag["poke-ball"] != startingBag()["poke-ball"] {
+    t.Errorf("expected a version 1 save to get the starting inventory, got $%d and %v", cfg.money, cfg.bag)
+  }
+}
+
+func TestInventoryIsSaved(t *testing.T) {
+  saveFilePath := filepath.Join(t.TempDir(), "pokedex.json")
+  cfg := &config{
+    ownedPokemon: []ownedPokemon{},
+    saveFilePath: saveFilePath,
+    money: 1234,
+    bag: map[string]int{"great-ball": 2, "potion": 1},
+  }
+  if err := saveCaughtPokemon(cfg); err != nil {
+    t.Fatalf("unexpected error saving: %v", err)
+  }
+
+  loaded := &config{saveFilePath: saveFilePath}
+  if err := loadCaughtPokemon(loaded); err != nil {
+    t.Fatalf("unexpected error loading: %v", err)
+  }
+  if loaded.money != 1234 || loaded.bag["great-ball"] != 2 || loaded.bag["potion"] != 1 {
+    t.Errorf("expected the inventory to survive a save and load, got $%d and %v", loaded.money, loaded.bag)
+  }
+}
+
+func TestCorruptSaveFileSurvivesARun(t *testing.T) {
+  saveFilePath := filepath.Join(t.TempDir(), "pokedex.json")
+  corrupt := []byte(`{"version": 3, "owned_pokemon": [{"id": 1, "species": "pika`)
+  if err := os.WriteFile(saveFilePath, corrupt, 0o644); err != nil {
+    t.Fatal(err)
+  }
+
+  args, commandLine := os.Args, flag.CommandLine
+  t.Cleanup(func() {
+    os.Args, flag.CommandLine = args, commandLine
+  })
+  flag.CommandLine = flag.NewFlagSet("pokedex", flag.ContinueOnError)
+  os.Args = []string{"pokedex", "--save-file", saveFilePath, "--cache-dir", "", "--offline", "--history-file", "", "--seed", "1", "pokedex"}
+
+  run()
+
+  data, err := os.ReadFile(saveFilePath)
+  if err != nil || string(data) != string(corrupt) {
+    t.Errorf("expected the save file to be left alone, got %q %v", data, err)
+  }
+}
+
+func TestExplicitSaveAfterFailedLoad(t *testing.T) {
+  saveFilePath := filepath.Join(t.TempDir(), "pokedex.json")
+  if err := os.WriteFile(saveFilePath, []byte(`{"version": 99}`), 0o644); err != nil {
+    t.Fatal(err)
+  }
+
+  cfg := &config{saveFilePath: saveFilePath, ownedPokemon: []ownedPokemon{}, out: io.Discard}
+  if err := loadCaughtPokemon(cfg); err == nil {
+    t.Fatalf("expected an unsupported version to fail")
+  }
+
+  if err := autosave(cfg); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if data, _ := os.ReadFile(saveFilePath); string(data) != `{"version": 99}` {
+    t.Errorf("expected autosave to leave the file alone, got %q", data)
+  }
+
+  // saving on purpose overwrites it and turns autosave back on
+  if err := commandSave(cfg)(context.Background(), nil); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if cfg.autosaveBlocked {
+    t.Errorf("expected an explicit save to turn autosave back on")
+  }
+  if err := loadCaughtPokemon(cfg); err != nil {
+    t.Errorf("expected the new save file to load, got %v", err)
+  }
+}
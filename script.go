@@ -0,0 +1,69 @@
+package main
+
+import (
+  "context"
+  "bufio"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+)
+
+// stdinIsTerminal tells a person typing at the prompt apart from piped or redirected input
+func stdinIsTerminal() bool {
+  info, err := os.Stdin.Stat()
+  if err != nil {
+    return false
+  }
+
+  return info.Mode() & os.ModeCharDevice != 0
+}
+
+// stripComment drops everything from a # that starts a word, so whole comment lines end up empty
+func stripComment(line string) string {
+  for i, r := range line {
+    if r == '#' && (i == 0 || line[i - 1] == ' ' || line[i - 1] == '\t') {
+      return line[:i]
+    }
+  }
+
+  return line
+}
+
+// runScript runs the commands in r one line at a time without prompting. Errors go to stderr with
+// the line they came from, and with failFast the first one stops the script. Cancelling ctx always stops it.
+// It reports whether every command succeeded
+func runScript(ctx context.Context, commands map[string]cliCommand, r io.Reader, name string, failFast bool) bool {
+  succeeded := true
+
+  scanner := bufio.NewScanner(r)
+  lineNumber := 0
+  for scanner.Scan() {
+    lineNumber++
+
+    words := cleanInput(stripComment(scanner.Text()))
+    if len(words) == 0 {
+      continue
+    }
+
+    err := executeCommand(ctx, commands, words)
+    if errors.Is(err, errExit) {
+      return succeeded
+    }
+    if err != nil {
+      fmt.Fprintf(os.Stderr, "%s:%d: %v\n", name, lineNumber, err)
+      succeeded = false
+
+      if failFast || ctx.Err() != nil {
+        return false
+      }
+    }
+  }
+
+  if err := scanner.Err(); err != nil {
+    fmt.Fprintf(os.Stderr, "%s: error reading script %v\n", name, err)
+    return false
+  }
+
+  return succeeded
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "strings"
+  "testing"
+)
+
+func TestRunScript(t *testing.T) {
+  ran := []string{}
+  commands := map[string]cliCommand{
+    "ok": {
+      name: "ok",
+      maxArgs: -1,
+      callback: func(ctx context.Context, args []string) error {
+        ran = append(ran, "ok " + strings.Join(args, " "))
+        return nil
+      },
+    },
+    "fail": {
+      name: "fail",
+      callback: func(ctx context.Context, args []string) error {
+        ran = append(ran, "fail")
+        return fmt.Errorf("failed")
+      },
+    },
+    "exit": {
+      name: "exit",
+      callback: commandExit,
+    },
+  }
+
+  cases := []struct {
+    name string
+    script string
+    failFast bool
+    expectedRan []string
+    expectedSucceeded bool
+  }{
+    {
+      name: "comments and blank lines are skipped",
+      script: "# setup\n\nok a # trailing comment\nok b\n",
+      expectedRan: []string{"ok a", "ok b"},
+      expectedSucceeded: true,
+    },
+    {
+      name: "errors keep going without fail fast",
+      script: "fail\nok a\n",
+      expectedRan: []string{"fail", "ok a"},
+      expectedSucceeded: false,
+    },
+    {
+      name: "fail fast stops at the first error",
+      script: "fail\nok a\n",
+      failFast: true,
+      expectedRan: []string{"fail"},
+      expectedSucceeded: false,
+    },
+    {
+      name: "unknown commands fail",
+      script: "bogus\n",
+      expectedRan: []string{},
+      expectedSucceeded: false,
+    },
+    {
+      name: "exit stops the script",
+      script: "ok a\nexit\nok b\n",
+      expectedRan: []string{"ok a"},
+      expectedSucceeded: true,
+    },
+  }
+
+  for _, c := range cases {
+    t.Run(c.name, func(t *testing.T) {
+      ran = []string{}
+      succeeded := runScript(context.Background(), commands, strings.NewReader(c.script), "test", c.failFast)
+
+      if succeeded != c.expectedSucceeded {
+        t.Errorf("expected succeeded to be %v", c.expectedSucceeded)
+      }
+      if strings.Join(ran, ",") != strings.Join(c.expectedRan, ",") {
+        t.Errorf("expected %v to run, got %v", c.expectedRan, ran)
+      }
+    })
+  }
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "io"
+  "math/rand"
+  "strconv"
+  "time"
+)
+
+// newSeed picks a seed for runs that weren't given one with --seed
+func newSeed() int64 {
+  return time.Now().UnixNano()
+}
+
+// reseed starts the session's random source over from seed, every catch roll after it is reproducible
+func (cfg *config) reseed(seed int64) {
+  cfg.seed = seed
+  cfg.rng = rand.New(rand.NewSource(seed))
+}
+
+func commandSeed(cfg *config) func(context.Context, []string) error {
+  return func(ctx context.Context, args []string) error {
+    if len(args) == 1 {
+      seed, err := strconv.ParseInt(args[0], 10, 64)
+      if err != nil {
+        return fmt.Errorf("seed must be a whole number, not %q", args[0])
+      }
+      cfg.reseed(seed)
+    }
+
+    result := seedResult{Seed: cfg.seed}
+    return cfg.emit(result, func(w io.Writer) {
+      fmt.Fprintf(w, "Random seed is %d\n", result.Seed)
+    })
+  }
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "fmt"
+  "net/http"
+  "reflect"
+  "testing"
+)
+
+func newSeedTestConfig(t *testing.T) (*config, *bytes.Buffer) {
+  cfg, out := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    fmt.Fprint(w, `{"name": "pikachu", "capture_rate": 190, "stats": [{"base_stat": 35, "stat": {"name": "hp"}}]}`)
+  })
+  cfg.output = outputJSON
+  cfg.sandbox = true
+  cfg.bag["poke-ball"] = 100
+
+  return cfg, out
+}
+
+// catchOutcomes throws n poke balls at pikachu and lists how each throw went
+func catchOutcomes(t *testing.T, cfg *config, out *bytes.Buffer, n int) []string {
+  outcomes := []string{}
+  for i := 0; i < n; i++ {
+    out.Reset()
+    if err := commandCatch(cfg)(context.Background(), []string{"pikachu"}); err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+
+    var result catchResult
+    if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+      t.Fatalf("unexpected output %q: %v", out.String(), err)
+    }
+    outcomes = append(outcomes, result.Outcome)
+  }
+
+  return outcomes
+}
+
+func TestSameSeedSameCatches(t *testing.T) {
+  first, firstOut := newSeedTestConfig(t)
+  first.reseed(42)
+  second, secondOut := newSeedTestConfig(t)
+  second.reseed(42)
+
+  expected := catchOutcomes(t, first, firstOut, 20)
+  actual := catchOutcomes(t, second, secondOut, 20)
+  if !reflect.DeepEqual(expected, actual) {
+    t.Errorf("expected the same seed to catch the same way, got %v and %v", expected, actual)
+  }
+}
+
+func TestSeedCommand(t *testing.T) {
+  cfg, out := newSeedTestConfig(t)
+
+  expected := catchOutcomes(t, cfg, out, 20)
+
+  out.Reset()
+  if err := commandSeed(cfg)(context.Background(), []string{"1"}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if out.String() != "{\"seed\":1}\n" {
+    t.Errorf("expected the seed to be echoed, got %q", out.String())
+  }
+
+  // newTestConfig starts from seed 1 too, so reseeding replays the same throws
+  if actual := catchOutcomes(t, cfg, out, 20); !reflect.DeepEqual(expected, actual) {
+    t.Errorf("expected reseeding to replay the catches, got %v and %v", expected, actual)
+  }
+
+  if err := commandSeed(cfg)(context.Background(), []string{"lots"}); err == nil {
+    t.Errorf("expected a seed that isn't a number to fail")
+  }
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+  "context"
+  "sort"
+  "strings"
+)
+
+// maxSuggestions is how many "did you mean" names are offered at most
+const maxSuggestions = 3
+
+// suggestionError adds the closest known names to err, errors.Is/As still see err underneath
+type suggestionError struct {
+  err error
+  suggestions []string
+}
+
+func (e *suggestionError) Error() string {
+  return e.err.Error() + ", did you mean " + e.alternatives() + "?"
+}
+
+func (e *suggestionError) Unwrap() error {
+  return e.err
+}
+
+// alternatives reads like "pikachu, pichu or raichu"
+func (e *suggestionError) alternatives() string {
+  last := len(e.suggestions) - 1
+  if last == 0 {
+    return e.suggestions[0]
+  }
+
+  return strings.Join(e.suggestions[:last], ", ") + " or " + e.suggestions[last]
+}
+
+// didYouMean wraps err with the names closest to name, err is returned as it is when nothing is close
+func didYouMean(err error, name string, names []string) error {
+  suggestions := closestNames(name, names)
+  if len(suggestions) == 0 {
+    return err
+  }
+
+  return &suggestionError{err: err, suggestions: suggestions}
+}
+
+// closestNames is up to maxSuggestions of names within a few edits of name, closest first
+func closestNames(name string, names []string) []string {
+  // a typo or two, but not so many that short names match everything
+  maxDistance := max(1, min(3, len(name) / 3))
+
+  type candidate struct {
+    name string
+    distance int
+  }
+  candidates := []candidate{}
+  for _, other := range names {
+    if other == name {
+      continue
+    }
+    if distance := editDistance(name, other); distance <= maxDistance {
+      candidates = append(candidates, candidate{name: other, distance: distance})
+    }
+  }
+
+  sort.Slice(candidates, func(i, j int) bool {
+    if candidates[i].distance != candidates[j].distance {
+      return candidates[i].distance < candidates[j].distance
+    }
+    return candidates[i].name < candidates[j].name
+  })
+
+  closest := []string{}
+  for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+    closest = append(closest, c.name)
+  }
+
+  return closest
+}
+
+// editDistance is the Levenshtein distance, how many single character inserts, deletes or swaps turn a into b
+func editDistance(a, b string) int {
+  ra, rb := []rune(a), []rune(b)
+
+  // only the previous row of the table is needed to fill in the next
+  previous := make([]int, len(rb) + 1)
+  current := make([]int, len(rb) + 1)
+  for j := range previous {
+    previous[j] = j
+  }
+
+  for i := 1; i <= len(ra); i++ {
+    current[0] = i
+    for j := 1; j <= len(rb); j++ {
+      cost := 1
+      if ra[i - 1] == rb[j - 1] {
+        cost = 0
+      }
+      current[j] = min(previous[j] + 1, current[j - 1] + 1, previous[j - 1] + cost)
+    }
+    previous, current = current, previous
+  }
+
+  return previous[len(rb)]
+}
+
+// pokemonNames is the name index for catch suggestions, fetched once per session.
+// Suggestions are best effort, so a failed fetch just means none are offered
+func (cfg *config) pokemonNames(ctx context.Context) []string {
+  if cfg.pokemonNameIndex == nil {
+    names, err := cfg.pokeapiClient.PokemonNames(ctx)
+    if err != nil {
+      return nil
+    }
+    cfg.pokemonNameIndex = names
+  }
+
+  return cfg.pokemonNameIndex
+}
+
+// locationAreaNames is the name index for explore suggestions, fetched once per session
+func (cfg *config) locationAreaNames(ctx context.Context) []string {
+  if cfg.locationAreaNameIndex == nil {
+    names, err := cfg.pokeapiClient.LocationAreaNames(ctx)
+    if err != nil {
+      return nil
+    }
+    cfg.locationAreaNameIndex = names
+  }
+
+  return cfg.locationAreaNameIndex
+}
+
+// commandNames is every name and alias a command can be run by
+func commandNames(commands map[string]cliCommand) []string {
+  names := []string{}
+  for _, command := range commands {
+    names = append(names, command.name)
+    names = append(names, command.aliases...)
+  }
+
+  return names
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net/http"
+  "reflect"
+  "testing"
+
+  "github.com/Pradhyumna789/Pokedex_Cli/internal/pokeapi"
+)
+
+func TestEditDistance(t *testing.T) {
+  cases := []struct {
+    a string
+    b string
+    expected int
+  }{
+    {a: "", b: "", expected: 0},
+    {a: "", b: "abc", expected: 3},
+    {a: "pikachu", b: "pikachu", expected: 0},
+    {a: "pikachuu", b: "pikachu", expected: 1},
+    {a: "pikchu", b: "pikachu", expected: 1},
+    {a: "pikacho", b: "pikachu", expected: 1},
+    {a: "kitten", b: "sitting", expected: 3},
+    {a: "flaaffy", b: "flaafy", expected: 1},
+    {a: "mr-mime", b: "mime-jr", expected: 6},
+  }
+
+  for _, c := range cases {
+    if actual := editDistance(c.a, c.b); actual != c.expected {
+      t.Errorf("editDistance(%q, %q) = %d, expected %d", c.a, c.b, actual, c.expected)
+    }
+    if actual := editDistance(c.b, c.a); actual != c.expected {
+      t.Errorf("editDistance(%q, %q) = %d, expected %d", c.b, c.a, actual, c.expected)
+    }
+  }
+}
+
+func TestClosestNames(t *testing.T) {
+  names := []string{"pikachu", "pichu", "raichu", "bulbasaur", "pikachu-rock-star"}
+
+  cases := []struct {
+    name string
+    expected []string
+  }{
+    {name: "pikachuu", expected: []string{"pikachu"}},
+    {name: "pichuu", expected: []string{"pichu"}},
+    {name: "charmander", expected: []string{}},
+    {name: "pikachu", expected: []string{"pichu"}},
+  }
+
+  for _, c := range cases {
+    if actual := closestNames(c.name, names); !reflect.DeepEqual(actual, c.expected) {
+      t.Errorf("closestNames(%q) = %v, expected %v", c.name, actual, c.expected)
+    }
+  }
+}
+
+func TestCatchSuggestsNames(t *testing.T) {
+  cfg, _ := newTestConfig(t, func(w http.ResponseWriter, r *http.Request, serverURL string) {
+    if r.URL.Path == "/pokemon" {
+      fmt.Fprint(w, `{"results": [{"name": "pikachu"}, {"name": "pichu"}, {"name": "bulbasaur"}]}`)
+      return
+    }
+    http.NotFound(w, r)
+  })
+
+  cfg.sandbox = true
+  err := commandCatch(cfg)(context.Background(), []string{"pikachuu"})
+  if err == nil || err.Error() != "no Pokemon named 'pikachuu', did you mean pikachu?" {
+    t.Errorf("expected a suggestion, got %v", err)
+  }
+  if !errors.Is(err, pokeapi.ErrNotFound) {
+    t.Errorf("expected the error to wrap pokeapi.ErrNotFound")
+  }
+}
+
+func TestUnknownCommandSuggestsNames(t *testing.T) {
+  commands := map[string]cliCommand{
+    "explore": {name: "explore"},
+    "pokedex": {name: "pokedex", aliases: []string{"dex"}},
+  }
+
+  err := executeCommand(context.Background(), commands, []string{"explroe"})
+  if !errors.Is(err, errUnknownCommand) {
+    t.Fatalf("expected errUnknownCommand, got %v", err)
+  }
+
+  var suggestion *suggestionError
+  if !errors.As(err, &suggestion) || suggestion.alternatives() != "explore" {
+    t.Errorf("expected explore to be suggested, got %v", err)
+  }
+}